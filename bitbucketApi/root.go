@@ -0,0 +1,226 @@
+// Copyright © 2019 Robert Sotomski <sotomski@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitbucketApi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/sotomskir/goops/utils"
+	"github.com/spf13/viper"
+	"gopkg.in/resty.v1"
+	"net/url"
+	"time"
+)
+
+// PullRequest is pull request of Bitbucket Cloud or Bitbucket Server
+type PullRequest struct {
+	Id           int
+	Title        string
+	Description  string
+	SourceBranch string
+}
+
+type Commit struct {
+	Hash    string
+	Message string
+}
+
+type cloudPullRequest struct {
+	Id          int    `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Source      struct {
+		Branch struct {
+			Name string `json:"name"`
+		} `json:"branch"`
+	} `json:"source"`
+}
+
+type cloudCommits struct {
+	Values []struct {
+		Hash    string `json:"hash"`
+		Message string `json:"message"`
+	} `json:"values"`
+	Next string `json:"next"`
+}
+
+type serverPullRequest struct {
+	Id          int    `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	FromRef     struct {
+		DisplayId string `json:"displayId"`
+	} `json:"fromRef"`
+}
+
+type serverCommits struct {
+	Values []struct {
+		Id      string `json:"id"`
+		Message string `json:"message"`
+	} `json:"values"`
+	IsLastPage    bool `json:"isLastPage"`
+	NextPageStart int  `json:"nextPageStart"`
+}
+
+var client *resty.Client
+
+// Initialize configures Bitbucket client. Bitbucket Cloud API is used by default,
+// set GOOPSC_BITBUCKET_SERVER=true and GOOPSC_BITBUCKET_URL to Bitbucket Server url for Bitbucket Server REST API.
+// GOOPSC_BITBUCKET_TOKEN is used as bearer token when set, otherwise user and (app) password are used.
+func Initialize() {
+	viper.SetDefault("GOOPSC_BITBUCKET_URL", "https://api.bitbucket.org/2.0")
+	viper.SetDefault("GOOPSC_BITBUCKET_SERVER", "false")
+	client = resty.New()
+	client.SetHostURL(viper.GetString("GOOPSC_BITBUCKET_URL"))
+	client.SetTimeout(1 * time.Minute)
+
+	// Headers for all request
+	client.SetHeader("Accept", "application/json")
+	client.SetHeaders(map[string]string{
+		"Content-Type": "application/json",
+		"User-Agent":   "goops",
+	})
+	if token := viper.GetString("GOOPSC_BITBUCKET_TOKEN"); token != "" {
+		client.SetAuthToken(token)
+	} else if user := viper.GetString("GOOPSC_BITBUCKET_USER"); user != "" {
+		client.SetBasicAuth(user, viper.GetString("GOOPSC_BITBUCKET_PASSWORD"))
+	}
+}
+
+func validate() {
+	utils.ViperValidateEnv("GOOPSC_BITBUCKET_URL")
+	if viper.GetString("GOOPSC_BITBUCKET_TOKEN") == "" && viper.GetString("GOOPSC_BITBUCKET_USER") == "" {
+		logrus.Fatalln("Bitbucket credentials are not set, use GOOPSC_BITBUCKET_TOKEN or GOOPSC_BITBUCKET_USER and GOOPSC_BITBUCKET_PASSWORD variables")
+	}
+}
+
+func isServer() bool {
+	return utils.IsEnabled("GOOPSC_BITBUCKET_SERVER")
+}
+
+// GetRepository returns project (workspace on Bitbucket Cloud) and repository slug.
+// Bitbucket Pipelines variables are used unless GOOPSC_BITBUCKET_PROJECT and GOOPSC_BITBUCKET_REPOSITORY are set.
+func GetRepository() (string, string) {
+	project := viper.GetString("GOOPSC_BITBUCKET_PROJECT")
+	if project == "" {
+		project = viper.GetString("BITBUCKET_WORKSPACE")
+	}
+	repo := viper.GetString("GOOPSC_BITBUCKET_REPOSITORY")
+	if repo == "" {
+		repo = viper.GetString("BITBUCKET_REPO_SLUG")
+	}
+	return project, repo
+}
+
+func get(endpoint string, response interface{}) error {
+	validate()
+	res, err := client.R().Get(endpoint)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode() >= 400 {
+		return fmt.Errorf("GET: %s\nStatus code: %d\nResponse: %s", endpoint, res.StatusCode(), string(res.Body()))
+	}
+
+	jsonErr := json.Unmarshal(res.Body(), response)
+
+	if jsonErr != nil {
+		return fmt.Errorf("GET: %s\nStatusCode: %d\nServer responded with invalid JSON: %s\nResponse: %s", endpoint, res.StatusCode(), jsonErr, string(res.Body()))
+	}
+	return nil
+}
+
+func post(endpoint string, payload interface{}, response interface{}) {
+	validate()
+	res, err := client.R().SetBody(payload).Post(endpoint)
+	if err != nil {
+		logrus.Fatalln(err)
+	}
+	if res.StatusCode() >= 400 {
+		logrus.Fatalf("POST: %s\nStatus code: %d\nRequest: %#v\nResponse: %s\n", endpoint, res.StatusCode(), payload, string(res.Body()))
+	}
+
+	jsonErr := json.Unmarshal(res.Body(), response)
+	if jsonErr != nil {
+		logrus.Fatalf("POST: %s\nStatusCode: %d\nServer responded with invalid JSON: %s\nResponse: %s\n", endpoint, res.StatusCode(), jsonErr, string(res.Body()))
+	}
+}
+
+func pullRequestEndpoint(project string, repo string, id int) string {
+	if isServer() {
+		return fmt.Sprintf("/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d", url.PathEscape(project), url.PathEscape(repo), id)
+	}
+	return fmt.Sprintf("/repositories/%s/%s/pullrequests/%d", url.PathEscape(project), url.PathEscape(repo), id)
+}
+
+func GetPullRequest(project string, repo string, id int) (PullRequest, error) {
+	if isServer() {
+		pr := serverPullRequest{}
+		err := get(pullRequestEndpoint(project, repo, id), &pr)
+		return PullRequest{Id: pr.Id, Title: pr.Title, Description: pr.Description, SourceBranch: pr.FromRef.DisplayId}, err
+	}
+	pr := cloudPullRequest{}
+	err := get(pullRequestEndpoint(project, repo, id), &pr)
+	return PullRequest{Id: pr.Id, Title: pr.Title, Description: pr.Description, SourceBranch: pr.Source.Branch.Name}, err
+}
+
+// GetPullRequestCommits returns all commits of pull request, following pagination.
+func GetPullRequestCommits(project string, repo string, id int) ([]Commit, error) {
+	commits := make([]Commit, 0)
+	endpoint := pullRequestEndpoint(project, repo, id) + "/commits"
+	if isServer() {
+		for start := 0; ; {
+			page := serverCommits{}
+			if err := get(fmt.Sprintf("%s?start=%d", endpoint, start), &page); err != nil {
+				return nil, err
+			}
+			for _, c := range page.Values {
+				commits = append(commits, Commit{Hash: c.Id, Message: c.Message})
+			}
+			if page.IsLastPage || len(page.Values) == 0 {
+				return commits, nil
+			}
+			start = page.NextPageStart
+		}
+	}
+	for endpoint != "" {
+		page := cloudCommits{}
+		if err := get(endpoint, &page); err != nil {
+			return nil, err
+		}
+		for _, c := range page.Values {
+			commits = append(commits, Commit{Hash: c.Hash, Message: c.Message})
+		}
+		// next is absolute url of the next page
+		endpoint = page.Next
+	}
+	return commits, nil
+}
+
+// CreatePullRequestComment adds comment to pull request. Returns id of created comment.
+func CreatePullRequestComment(project string, repo string, id int, text string) int {
+	endpoint := pullRequestEndpoint(project, repo, id) + "/comments"
+	comment := struct {
+		Id int `json:"id"`
+	}{}
+	if isServer() {
+		post(endpoint, map[string]string{"text": text}, &comment)
+	} else {
+		post(endpoint, map[string]interface{}{"content": map[string]string{"raw": text}}, &comment)
+	}
+	return comment.Id
+}
@@ -0,0 +1,128 @@
+// Copyright © 2019 Robert Sotomski <sotomski@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bitbucketApi
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func initializeTestServer(server bool, handler http.HandlerFunc) *httptest.Server {
+	s := httptest.NewServer(handler)
+	viper.Set("GOOPSC_BITBUCKET_URL", s.URL)
+	viper.Set("GOOPSC_BITBUCKET_SERVER", fmt.Sprint(server))
+	viper.Set("GOOPSC_BITBUCKET_USER", "user")
+	viper.Set("GOOPSC_BITBUCKET_PASSWORD", "secret")
+	Initialize()
+	return s
+}
+
+func TestCloudPullRequest(t *testing.T) {
+	var server *httptest.Server
+	server = initializeTestServer(false, func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "secret" {
+			t.Errorf("Basic auth is invalid, got: %s %s", user, password)
+		}
+		switch r.URL.String() {
+		case "/repositories/team/app/pullrequests/3":
+			fmt.Fprint(w, `{"id":3,"title":"ABC-1 Login","description":"Fixes ABC-2","source":{"branch":{"name":"feature/ABC-3"}}}`)
+		case "/repositories/team/app/pullrequests/3/commits":
+			fmt.Fprintf(w, `{"values":[{"hash":"a1","message":"ABC-1 form"}],"next":"%s/repositories/team/app/pullrequests/3/commits?page=2"}`, server.URL)
+		case "/repositories/team/app/pullrequests/3/commits?page=2":
+			fmt.Fprint(w, `{"values":[{"hash":"b2","message":"ABC-4 test"}]}`)
+		case "/repositories/team/app/pullrequests/3/comments":
+			body, _ := ioutil.ReadAll(r.Body)
+			if string(body) != `{"content":{"raw":"Version: 1.2.0"}}` {
+				t.Errorf("Comment is invalid, got: %s", body)
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id":10}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+	})
+	defer server.Close()
+
+	pr, _ := GetPullRequest("team", "app", 3)
+	if pr != (PullRequest{Id: 3, Title: "ABC-1 Login", Description: "Fixes ABC-2", SourceBranch: "feature/ABC-3"}) {
+		t.Errorf("Pull request is invalid, got: %#v", pr)
+	}
+	commits, _ := GetPullRequestCommits("team", "app", 3)
+	if fmt.Sprint(commits) != fmt.Sprint([]Commit{{"a1", "ABC-1 form"}, {"b2", "ABC-4 test"}}) {
+		t.Errorf("Commits are invalid, got: %v", commits)
+	}
+	if id := CreatePullRequestComment("team", "app", 3, "Version: 1.2.0"); id != 10 {
+		t.Errorf("Comment id is invalid, got: %d", id)
+	}
+}
+
+func TestTokenAuth(t *testing.T) {
+	server := initializeTestServer(false, func(w http.ResponseWriter, r *http.Request) {
+		if authorization := r.Header.Get("Authorization"); authorization != "Bearer token" {
+			t.Errorf("Authorization is invalid, got: %s", authorization)
+		}
+		fmt.Fprint(w, `{"id":3,"title":"ABC-1 Login"}`)
+	})
+	defer server.Close()
+	viper.Set("GOOPSC_BITBUCKET_USER", "")
+	viper.Set("GOOPSC_BITBUCKET_TOKEN", "token")
+	defer viper.Set("GOOPSC_BITBUCKET_TOKEN", "")
+	Initialize()
+
+	if pr, err := GetPullRequest("team", "app", 3); err != nil || pr.Title != "ABC-1 Login" {
+		t.Errorf("Pull request is invalid, got: %#v, err: %v", pr, err)
+	}
+}
+
+func TestServerPullRequest(t *testing.T) {
+	server := initializeTestServer(true, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.String() {
+		case "/rest/api/1.0/projects/PROJ/repos/app/pull-requests/3":
+			fmt.Fprint(w, `{"id":3,"title":"ABC-1 Login","description":"Fixes ABC-2","fromRef":{"displayId":"feature/ABC-3"}}`)
+		case "/rest/api/1.0/projects/PROJ/repos/app/pull-requests/3/commits?start=0":
+			fmt.Fprint(w, `{"values":[{"id":"a1","message":"ABC-1 form"}],"isLastPage":false,"nextPageStart":1}`)
+		case "/rest/api/1.0/projects/PROJ/repos/app/pull-requests/3/commits?start=1":
+			fmt.Fprint(w, `{"values":[{"id":"b2","message":"ABC-4 test"}],"isLastPage":true}`)
+		case "/rest/api/1.0/projects/PROJ/repos/app/pull-requests/3/comments":
+			body, _ := ioutil.ReadAll(r.Body)
+			if !strings.Contains(string(body), `"text":"Version: 1.2.0"`) {
+				t.Errorf("Comment is invalid, got: %s", body)
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id":11}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+	})
+	defer server.Close()
+	defer viper.Set("GOOPSC_BITBUCKET_SERVER", "false")
+
+	pr, _ := GetPullRequest("PROJ", "app", 3)
+	if pr.SourceBranch != "feature/ABC-3" || pr.Title != "ABC-1 Login" {
+		t.Errorf("Pull request is invalid, got: %#v", pr)
+	}
+	commits, _ := GetPullRequestCommits("PROJ", "app", 3)
+	if fmt.Sprint(commits) != fmt.Sprint([]Commit{{"a1", "ABC-1 form"}, {"b2", "ABC-4 test"}}) {
+		t.Errorf("Commits are invalid, got: %v", commits)
+	}
+	if id := CreatePullRequestComment("PROJ", "app", 3, "Version: 1.2.0"); id != 11 {
+		t.Errorf("Comment id is invalid, got: %d", id)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright © 2019 Robert Sotomski <sotomski@gmail.com>
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/sotomskir/goops/features/bitbucket"
+	"github.com/sotomskir/goops/features/semver"
+	"github.com/spf13/cobra"
+)
+
+// bitbucketCommentCmd represents the bitbucket comment command
+var bitbucketCommentCmd = &cobra.Command{
+	Use:   "comment [MESSAGE]",
+	Short: "Comment pull request with computed version",
+	Long: `Comment pull request with computed version, or with given message.
+Pull request id is read from BITBUCKET_PR_ID, or from previous merge commit message.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var message string
+		if len(args) > 0 {
+			message = args[0]
+		} else {
+			s := semver.New()
+			version, err := s.Version()
+			if err != nil {
+				logrus.Fatalln(err)
+			}
+			message = fmt.Sprintf("Version: %s", version)
+		}
+		if err := bitbucket.Comment(message); err != nil {
+			logrus.Fatalln(err)
+		}
+	},
+}
+
+func init() {
+	bitbucketCmd.AddCommand(bitbucketCommentCmd)
+}
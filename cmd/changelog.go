@@ -0,0 +1,83 @@
+// Copyright © 2019 Robert Sotomski <sotomski@gmail.com>
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/sotomskir/goops/features/changelog"
+	"github.com/sotomskir/goops/features/semver"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	changelogComponent string
+	changelogVersion   string
+	changelogWrite     bool
+)
+
+// changelogCmd represents the changelog command
+var changelogCmd = &cobra.Command{
+	Use:   "changelog",
+	Short: "Generate changelog from commits since previous tag",
+	Long: `Generate changelog from commits since previous tag.
+Commits are grouped by Conventional Commit type, or by "Changelog: <label>" trailer when --group-by=label is used.
+Jira issue keys found in commit messages are linked when GOOPSC_JIRA_SERVER_URL is set.
+Changelog is rendered as Markdown, --write flag inserts it into Keep a Changelog file.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		s := semver.New()
+		if changelogComponent != "" {
+			c, err := s.Component(changelogComponent)
+			if err != nil {
+				logrus.Fatalln(err)
+			}
+			s = c
+		}
+		version := changelogVersion
+		if version == "" {
+			var err error
+			version, err = changelog.ReleaseVersion(s)
+			if err != nil {
+				logrus.Fatalln(err)
+			}
+		}
+		c, err := changelog.Generate(s, version)
+		if err != nil {
+			logrus.Fatalln(err)
+		}
+		fmt.Print(c.Markdown())
+		if changelogWrite {
+			if err := changelog.UpdateFile(viper.GetString(changelog.GoopscChangelogFile), c); err != nil {
+				logrus.Fatalln(err)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(changelogCmd)
+
+	changelogCmd.Flags().StringVarP(&changelogComponent, "component", "c", "", "Generate changelog for given component")
+	changelogCmd.Flags().StringVar(&changelogVersion, "version", "", "Version used in changelog heading (default is HEAD tag version or next release version)")
+	changelogCmd.Flags().BoolVarP(&changelogWrite, "write", "w", false, "Insert changelog into changelog file")
+	changelogCmd.Flags().String("file", "CHANGELOG.md", "Changelog file")
+	changelogCmd.Flags().String("group-by", changelog.GroupByType, "Group commits by: type or label")
+
+	viper.BindPFlag(changelog.GoopscChangelogFile, changelogCmd.Flags().Lookup("file"))
+	viper.BindPFlag(changelog.GoopscChangelogGroupBy, changelogCmd.Flags().Lookup("group-by"))
+}
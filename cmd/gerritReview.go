@@ -0,0 +1,55 @@
+// Copyright © 2019 Robert Sotomski <sotomski@gmail.com>
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/sotomskir/goops/features/gerrit"
+	"github.com/spf13/cobra"
+)
+
+var gerritReviewChange string
+var gerritReviewRevision string
+var gerritReviewMessage string
+var gerritReviewLabels []string
+var gerritReviewComments []string
+
+// gerritReviewCmd represents the gerrit review command
+var gerritReviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Post review votes and comments on Gerrit change",
+	Long: `Post review with label votes, message and inline comments on Gerrit change.
+Change and revision are read from GERRIT_CHANGE_NUMBER and GERRIT_PATCHSET_REVISION set by Jenkins Gerrit Trigger.
+
+Example:
+goops gerrit review -l Verified=+1 -m "Build successful"
+goops gerrit review -l Verified=-1 --comment "main.go:12:Test failed"`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := gerrit.Review(gerritReviewChange, gerritReviewRevision, gerritReviewMessage, gerritReviewLabels, gerritReviewComments); err != nil {
+			logrus.Fatalln(err)
+		}
+	},
+}
+
+func init() {
+	gerritCmd.AddCommand(gerritReviewCmd)
+	gerritReviewCmd.Flags().StringVarP(&gerritReviewMessage, "message", "m", "", "Review message")
+	gerritReviewCmd.Flags().StringArrayVarP(&gerritReviewLabels, "label", "l", []string{}, "Label vote in Label=value format e.g. Verified=+1, can be repeated")
+	gerritReviewCmd.Flags().StringArrayVar(&gerritReviewComments, "comment", []string{}, "Inline comment in path:line:message format, line 0 comments whole file, can be repeated")
+	gerritReviewCmd.Flags().StringVar(&gerritReviewChange, "change", "", "Change number or id (default is GERRIT_CHANGE_NUMBER)")
+	gerritReviewCmd.Flags().StringVar(&gerritReviewRevision, "revision", "", "Patch set revision (default is GERRIT_PATCHSET_REVISION or current)")
+}
@@ -0,0 +1,85 @@
+// Copyright © 2019 Robert Sotomski <sotomski@gmail.com>
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/sotomskir/goops/features/jira"
+	"github.com/sotomskir/goops/features/semver"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"time"
+)
+
+var jiraReleaseVersion string
+var jiraReleaseDate string
+var jiraReleaseProjects []string
+var jiraReleaseMoveTo string
+var jiraReleaseUnresolved string
+
+// jiraReleaseCmd represents the jira release command
+var jiraReleaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Mark Jira version as released",
+	Long: `Mark Jira version as released and set its release date.
+Version is read from GOOPS_SEMVER_RELEASE, or computed when it is not set.
+Issues of version that are not done are moved to --move-unresolved-to version when set,
+otherwise release fails, or only warns with --unresolved warn.
+
+Example:
+goops jira release
+goops jira release --version 1.2.0 --move-unresolved-to 1.3.0`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		version := jiraReleaseVersion
+		if version == "" {
+			version = viper.GetString(semver.GoopsSemverRelease)
+		}
+		if version == "" {
+			s := semver.New()
+			v, err := s.Version()
+			if err != nil {
+				logrus.Fatalln(err)
+			}
+			version = v
+		}
+		options := jira.ReleaseOptions{
+			Projects:         jiraReleaseProjects,
+			MoveUnresolvedTo: jiraReleaseMoveTo,
+			Unresolved:       jiraReleaseUnresolved,
+		}
+		if jiraReleaseDate != "" {
+			date, err := time.Parse("2006-01-02", jiraReleaseDate)
+			if err != nil {
+				logrus.Fatalln(err)
+			}
+			options.Date = date
+		}
+		j := jira.New()
+		if err := j.ReleaseVersion(version, options); err != nil {
+			logrus.Fatalln(err)
+		}
+	},
+}
+
+func init() {
+	jiraCmd.AddCommand(jiraReleaseCmd)
+	jiraReleaseCmd.Flags().StringVar(&jiraReleaseVersion, "version", "", "Version to release (default is GOOPS_SEMVER_RELEASE or computed version)")
+	jiraReleaseCmd.Flags().StringVar(&jiraReleaseDate, "date", "", "Release date in YYYY-MM-DD format (default is today)")
+	jiraReleaseCmd.Flags().StringArrayVarP(&jiraReleaseProjects, "project", "p", []string{}, "Jira project key, can be repeated (default is GOOPSC_JIRA_PROJECT_KEY)")
+	jiraReleaseCmd.Flags().StringVar(&jiraReleaseMoveTo, "move-unresolved-to", "", "Move issues that are not done to given version before release")
+	jiraReleaseCmd.Flags().StringVar(&jiraReleaseUnresolved, "unresolved", "", "What to do when issues are not done, one of: fail, warn, ignore (default is GOOPSC_JIRA_RELEASE_UNRESOLVED or fail)")
+}
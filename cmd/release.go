@@ -0,0 +1,71 @@
+// Copyright © 2019 Robert Sotomski <sotomski@gmail.com>
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	releaseFeature "github.com/sotomskir/goops/features/release"
+	"github.com/sotomskir/goops/features/semver"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	bumpLevel        string
+	releaseComponent string
+)
+
+// releaseCmd represents the release command
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Tag HEAD with next release version and push tag to remote",
+	Long: `Tag HEAD with next release version and push tag to remote.
+Release version is computed by semver strategy, or by bumping previous tag when --bump flag is used.
+Tag is annotated and optionally signed. Command will fail when working tree is dirty or HEAD is already tagged.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		s := semver.New()
+		if releaseComponent != "" {
+			c, err := s.Component(releaseComponent)
+			if err != nil {
+				logrus.Fatalln(err)
+			}
+			s = c
+		}
+		tag, err := releaseFeature.Release(s, bumpLevel)
+		if err != nil {
+			logrus.Fatalln(err)
+		}
+		fmt.Println(tag)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(releaseCmd)
+
+	releaseCmd.Flags().StringVarP(&bumpLevel, "bump", "b", "", "Bump previous version: major, minor or patch (default is computed by semver strategy)")
+	releaseCmd.Flags().StringVarP(&releaseComponent, "component", "c", "", "Release given component")
+	releaseCmd.Flags().String("remote", "origin", "Remote to push tag to")
+	releaseCmd.Flags().Bool("push", true, "Push tag to remote")
+	releaseCmd.Flags().Bool("sign", false, "Sign tag with GPG or SSH key configured in git")
+	releaseCmd.Flags().String("signing-key", "", "Key used to sign tag")
+
+	viper.BindPFlag(releaseFeature.GoopscReleaseRemote, releaseCmd.Flags().Lookup("remote"))
+	viper.BindPFlag(releaseFeature.GoopscReleasePush, releaseCmd.Flags().Lookup("push"))
+	viper.BindPFlag(releaseFeature.GoopscReleaseSign, releaseCmd.Flags().Lookup("sign"))
+	viper.BindPFlag(releaseFeature.GoopscReleaseSigningKey, releaseCmd.Flags().Lookup("signing-key"))
+}
@@ -0,0 +1,59 @@
+// Copyright © 2019 Robert Sotomski <sotomski@gmail.com>
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"github.com/sirupsen/logrus"
+	releaseFeature "github.com/sotomskir/goops/features/release"
+	"github.com/sotomskir/goops/features/semver"
+	"github.com/spf13/cobra"
+)
+
+var (
+	githubReleaseComponent string
+	githubReleaseDraft     bool
+)
+
+// releaseGithubCmd represents the release github command
+var releaseGithubCmd = &cobra.Command{
+	Use:   "github",
+	Short: "Create or update GitHub release for version tagged on HEAD",
+	Long: `Create or update GitHub release for version tagged on HEAD.
+Release notes are generated from commits since previous tag, the same way as by changelog command.
+Requires GOOPSC_GITHUB_TOKEN variable, repository is read from GITHUB_REPOSITORY or TRAVIS_REPO_SLUG
+unless GOOPSC_GITHUB_REPOSITORY is set. Use GOOPSC_GITHUB_API_URL for GitHub Enterprise.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		s := semver.New()
+		if githubReleaseComponent != "" {
+			c, err := s.Component(githubReleaseComponent)
+			if err != nil {
+				logrus.Fatalln(err)
+			}
+			s = c
+		}
+		if _, err := releaseFeature.PublishGithubRelease(s, githubReleaseDraft); err != nil {
+			logrus.Fatalln(err)
+		}
+	},
+}
+
+func init() {
+	releaseCmd.AddCommand(releaseGithubCmd)
+
+	releaseGithubCmd.Flags().StringVarP(&githubReleaseComponent, "component", "c", "", "Publish release of given component")
+	releaseGithubCmd.Flags().BoolVar(&githubReleaseDraft, "draft", false, "Create draft release")
+}
@@ -0,0 +1,60 @@
+// Copyright © 2019 Robert Sotomski <sotomski@gmail.com>
+//
+// This program is free software; you can redistribute it and/or
+// modify it under the terms of the GNU General Public License
+// as published by the Free Software Foundation; either version 2
+// of the License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"github.com/sirupsen/logrus"
+	releaseFeature "github.com/sotomskir/goops/features/release"
+	"github.com/sotomskir/goops/features/semver"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gitlabReleaseComponent  string
+	gitlabReleaseLinks      []string
+	gitlabReleaseMilestones []string
+)
+
+// releaseGitlabCmd represents the release gitlab command
+var releaseGitlabCmd = &cobra.Command{
+	Use:   "gitlab",
+	Short: "Create or update GitLab release for version tagged on HEAD",
+	Long: `Create or update GitLab release for version tagged on HEAD.
+Release notes are generated from commits since previous tag, the same way as by changelog command.
+Requires CI_PROJECT_ID, CI_API_V4_URL and CI_GITLAB_TOKEN variables.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		s := semver.New()
+		if gitlabReleaseComponent != "" {
+			c, err := s.Component(gitlabReleaseComponent)
+			if err != nil {
+				logrus.Fatalln(err)
+			}
+			s = c
+		}
+		if _, err := releaseFeature.PublishGitlabRelease(s, gitlabReleaseLinks, gitlabReleaseMilestones); err != nil {
+			logrus.Fatalln(err)
+		}
+	},
+}
+
+func init() {
+	releaseCmd.AddCommand(releaseGitlabCmd)
+
+	releaseGitlabCmd.Flags().StringVarP(&gitlabReleaseComponent, "component", "c", "", "Publish release of given component")
+	releaseGitlabCmd.Flags().StringArrayVarP(&gitlabReleaseLinks, "link", "l", nil, "Release asset link in name=url format e.g. \"Docker image=registry.example.com/app:1.2.0\"")
+	releaseGitlabCmd.Flags().StringArrayVarP(&gitlabReleaseMilestones, "milestone", "m", nil, "Title of milestone associated with release")
+}
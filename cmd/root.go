@@ -16,9 +16,12 @@ package cmd
 
 import (
 	"github.com/sirupsen/logrus"
+	"github.com/sotomskir/goops/bitbucketApi"
 	"github.com/sotomskir/goops/execService"
 	"github.com/sotomskir/goops/features/docker"
+	"github.com/sotomskir/goops/gerritApi"
 	"github.com/sotomskir/goops/gitService"
+	"github.com/sotomskir/goops/githubApi"
 	"github.com/sotomskir/goops/gitlabApi"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -103,5 +106,8 @@ func initConfig() {
 		viper.WriteConfigAs(".goops.yaml")
 	}
 	gitlabApi.Initialize()
+	githubApi.Initialize()
+	bitbucketApi.Initialize()
+	gerritApi.Initialize()
 	docker.Initialize(execService.Service{})
 }
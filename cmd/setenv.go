@@ -16,15 +16,19 @@
 package cmd
 
 import (
+	"github.com/sirupsen/logrus"
 	"github.com/sotomskir/goops/features/jira"
 	"github.com/sotomskir/goops/features/semver"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var (
-	summary string
-	description string
-	issueType string
+	summary         string
+	description     string
+	issueType       string
+	mergeRequestIid string
+	issueScope      string
 )
 
 // setenvCmd represents the pipelineCommon command
@@ -38,11 +42,24 @@ CI_SEMVER_RELEASE, CI_SEMVER, CI_ISSUES. This way allows to share variables betw
 Variables can be used in next stages by reading them from gitlab.env file, using command "source .goops.env". 
 If build is not in merge context CI_ISSUES will be fetched from previous merged merge request.
 All CI_ISSUES will be assigned to CI_SEMVER_RELEASE version in Jira. 
+With --issue-scope release issues are collected from all commits since previous release tag.
 `,
 	Run: func(cmd *cobra.Command, args []string) {
+		if mergeRequestIid != "" {
+			viper.Set("CI_MERGE_REQUEST_IID", mergeRequestIid)
+		}
+		if issueScope != "" {
+			viper.Set(jira.GoopscJiraIssueScope, issueScope)
+		}
 		s := semver.New()
 		j := jira.New()
-		version := s.GetVersion()
+		version, err := s.GetVersion()
+		if err != nil {
+			logrus.Fatalln(err)
+		}
+		if _, err := s.GetComponentVersions(); err != nil {
+			logrus.Fatalln(err)
+		}
 		issues := j.GetIssues()
 		j.SetJiraVersion(version, issues, summary, description, issueType)
 	},
@@ -53,6 +70,8 @@ func init() {
 	rootCmd.Flags().StringVarP(&summary, "summary", "s", "", "Deployment issue summary.")
 	rootCmd.Flags().StringVarP(&description, "description", "d", "", "Deployment issue description.")
 	rootCmd.Flags().StringVarP(&issueType, "issue-type", "t", "", "Deployment issue type.")
+	setenvCmd.Flags().StringVarP(&mergeRequestIid, "mr", "m", "", "Merge request iid (default is CI_MERGE_REQUEST_IID or merge request of previous merge)")
+	setenvCmd.Flags().StringVar(&issueScope, "issue-scope", "", "Where issues are collected from, one of: change, release (default is GOOPSC_JIRA_ISSUE_SCOPE)")
 	// Here you will define your flags and configuration settings.
 
 	// Cobra supports Pe rsistent Flags which will work for this command
@@ -16,25 +16,36 @@
 package cmd
 
 import (
+	"github.com/sirupsen/logrus"
 	"github.com/sotomskir/goops/features/jira"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+var workflow string
+
 // pipelineJiraTransitionCmd represents the pipelineJiraTransition command
 var transitionCmd = &cobra.Command{
 	Use:     "transition STATE",
 	Aliases: []string{"t"},
 	Short:   "Transition all issues to desired state",
-	Args:    cobra.ExactArgs(1),
+	Long: `Transition all issues to desired state. Issues are moved through each transition of shortest path
+from their current status to desired state, found in workflow definition.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		if workflow != "" {
+			viper.Set(jira.GoopscJiraWorkflow, workflow)
+		}
 		j := jira.New()
-		j.JiraTransition(viper.GetString("GOOPS_ISSUES"), args[0])
+		if err := j.JiraTransition(viper.GetString("GOOPS_ISSUES"), args[0]); err != nil {
+			logrus.Fatalln(err)
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(transitionCmd)
+	transitionCmd.Flags().StringVarP(&workflow, "workflow", "w", "", "Workflow definition file or http url (default is GOOPSC_JIRA_WORKFLOW or workflow.yaml)")
 
 	// Here you will define your flags and configuration settings.
 
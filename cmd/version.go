@@ -16,12 +16,17 @@
 package cmd
 
 import (
+	"fmt"
+	"github.com/sirupsen/logrus"
 	"github.com/sotomskir/goops/features/semver"
 	"github.com/spf13/cobra"
 )
 
 var (
-	release bool
+	release       bool
+	component     string
+	explain       bool
+	explainFormat string
 )
 
 // versionCmd represents the version command
@@ -34,13 +39,73 @@ var versionCmd = &cobra.Command{
 Version generation is based on git tags.
 If current HEAD is tagged then tag will be used as version.
 Else command will lookup for previous tag bump it's minor version, reset patch version and append '-SNAPSHOT'
-When there are no tags found version will be '0.1.0-SNAPSHOT'`,
+When there are no tags found version will be '0.1.0-SNAPSHOT'
+When components are configured version of each component is generated as well.
+Use --explain flag to print how the version was derived, as text or JSON.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		s := semver.New()
-		s.GetVersion()
+		if explain {
+			explainVersion(s)
+			return
+		}
+		if component != "" {
+			c, err := s.Component(component)
+			if err != nil {
+				logrus.Fatalln(err)
+			}
+			if _, err := c.GetVersion(); err != nil {
+				logrus.Fatalln(err)
+			}
+			return
+		}
+		if _, err := s.GetVersion(); err != nil {
+			logrus.Fatalln(err)
+		}
+		if _, err := s.GetComponentVersions(); err != nil {
+			logrus.Fatalln(err)
+		}
 	},
 }
 
+func explainVersion(s semver.Semver) {
+	versions := []semver.Semver{s}
+	if component != "" {
+		c, err := s.Component(component)
+		if err != nil {
+			logrus.Fatalln(err)
+		}
+		versions = []semver.Semver{c}
+	} else {
+		components, err := s.Components()
+		if err != nil {
+			logrus.Fatalln(err)
+		}
+		versions = append(versions, components...)
+	}
+	traces := make([]*semver.Trace, 0, len(versions))
+	for _, v := range versions {
+		trace, err := v.Explain()
+		if err != nil {
+			logrus.Fatalln(err)
+		}
+		traces = append(traces, trace)
+	}
+	switch explainFormat {
+	case "json":
+		out, err := semver.TracesToJSON(traces)
+		if err != nil {
+			logrus.Fatalln(err)
+		}
+		fmt.Println(out)
+	case "text":
+		for _, trace := range traces {
+			fmt.Print(trace)
+		}
+	default:
+		logrus.Fatalf("Unexpected format: %s, expected one of: text, json\n", explainFormat)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(versionCmd)
 
@@ -54,4 +119,7 @@ func init() {
 	// is called directly, e.g.:
 	// versionCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 	versionCmd.Flags().BoolVarP(&release, "release", "r", false, "Print release version (without -SNAPSHOT)")
+	versionCmd.Flags().StringVarP(&component, "component", "c", "", "Generate version only for given component")
+	versionCmd.Flags().BoolVar(&explain, "explain", false, "Print how the version was derived instead of exporting it")
+	versionCmd.Flags().StringVar(&explainFormat, "format", "text", "Explain output format: text or json")
 }
@@ -26,6 +26,7 @@ var e IService = Service{}
 
 type IService interface {
 	Exec(cmd string) (string, error)
+	ExecArgs(name string, args ...string) (string, error)
 	LogExec(cmd string)
 }
 
@@ -38,12 +39,19 @@ func Exec(cmd string) (string, error) {
 
 func (s Service) Exec(cmd string) (string, error) {
 	args := strings.Split(cmd, " ")
-	name := args[0]
-	arg := args[1:]
-	out, err := exec.Command(name, arg...).CombinedOutput()
+	return s.ExecArgs(args[0], args[1:]...)
+}
+
+// ExecArgs runs command with arguments passed as is, use it when arguments may contain spaces.
+func (s Service) ExecArgs(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).CombinedOutput()
 	return strings.Trim(string(out), " \n"), err
 }
 
+func ExecArgs(name string, args ...string) (string, error) {
+	return e.ExecArgs(name, args...)
+}
+
 func (s Service) LogExec(command string) {
 	args := strings.Split(command, " ")
 	name := args[0]
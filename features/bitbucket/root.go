@@ -0,0 +1,56 @@
+package bitbucket
+
+import (
+	"errors"
+	"github.com/sirupsen/logrus"
+	"github.com/sotomskir/goops/bitbucketApi"
+	"github.com/sotomskir/goops/gitService"
+	"github.com/spf13/viper"
+	"regexp"
+	"strconv"
+)
+
+// pullRequestMergeRegex matches merge commit messages of Bitbucket Cloud e.g. "Merged in feature (pull request #12)"
+// and Bitbucket Server e.g. "Pull request #12: Feature"
+var pullRequestMergeRegex = regexp.MustCompile("(?i)pull request #(\\d+)")
+
+// GetPullRequestId returns id of pull request built by Bitbucket Pipelines,
+// or id of previously merged pull request when build is not triggered by pull request.
+func GetPullRequestId() (int, error) {
+	if id, err := strconv.Atoi(viper.GetString("BITBUCKET_PR_ID")); err == nil {
+		return id, nil
+	}
+	msg, err := gitService.GetPreviousMergeMessage()
+	if err != nil {
+		return 0, err
+	}
+	id, found := ExtractPullRequestId(msg)
+	if !found {
+		return 0, errors.New("pull request not found, BITBUCKET_PR_ID is not set and previous merge commit does not reference pull request")
+	}
+	return id, nil
+}
+
+func ExtractPullRequestId(msg string) (int, bool) {
+	match := pullRequestMergeRegex.FindStringSubmatch(msg)
+	if match == nil {
+		return 0, false
+	}
+	id, err := strconv.Atoi(match[1])
+	return id, err == nil
+}
+
+// Comment posts comment on current pull request.
+func Comment(text string) error {
+	project, repo := bitbucketApi.GetRepository()
+	if project == "" || repo == "" {
+		return errors.New("Bitbucket repository is not set, use GOOPSC_BITBUCKET_PROJECT and GOOPSC_BITBUCKET_REPOSITORY variables")
+	}
+	id, err := GetPullRequestId()
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Commenting pull request: %d\n", id)
+	bitbucketApi.CreatePullRequestComment(project, repo, id, text)
+	return nil
+}
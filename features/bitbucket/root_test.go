@@ -0,0 +1,38 @@
+package bitbucket
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/sotomskir/goops/gitService"
+	"github.com/sotomskir/goops/mockExecService"
+	"github.com/spf13/viper"
+	"testing"
+)
+
+func TestGetPullRequestId(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tables := []struct {
+		prId     string
+		merge    string
+		expected int
+		error    bool
+	}{
+		{"4", "", 4, false},
+		{"", "Merged in feature/ABC-1 (pull request #12)\n\nABC-1 login", 12, false},
+		{"", "Pull request #13: ABC-1 login\n\nMerge in PROJ/app from feature/ABC-1 to master", 13, false},
+		{"", "Merge branch 'feature' into master", 0, true},
+	}
+
+	defer viper.Set("BITBUCKET_PR_ID", "")
+	for _, table := range tables {
+		viper.Set("BITBUCKET_PR_ID", table.prId)
+		mockIService := mock_execService.NewMockIService(ctrl)
+		mockIService.EXPECT().Exec("git --no-pager log -1 --merges").Return(table.merge, nil).AnyTimes()
+		gitService.Initialize(mockIService)
+		actual, err := GetPullRequestId()
+		if actual != table.expected || (err != nil) != table.error {
+			t.Errorf("got: %d, err: %v, want: %d, %v", actual, err, table.expected, table)
+		}
+	}
+}
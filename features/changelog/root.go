@@ -0,0 +1,240 @@
+package changelog
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/sotomskir/goops/features/jira"
+	"github.com/sotomskir/goops/features/semver"
+	"github.com/sotomskir/goops/gitService"
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// Configuration variables
+	GoopscChangelogGroupBy = "GOOPSC_CHANGELOG_GROUP_BY"
+	GoopscChangelogFile    = "GOOPSC_CHANGELOG_FILE"
+
+	// Configuration options
+	GroupByType  = "type"
+	GroupByLabel = "label"
+)
+
+func setDefaults() {
+	viper.SetDefault(GoopscChangelogGroupBy, GroupByType)
+	viper.SetDefault(GoopscChangelogFile, "CHANGELOG.md")
+}
+
+// now is replaced in tests
+var now = time.Now
+
+const (
+	breakingSection = "Breaking Changes"
+	otherSection    = "Other Changes"
+)
+
+// typeSections maps conventional commit types to section titles, order of sections in changelog follows this list.
+var typeSections = []struct {
+	commitType string
+	title      string
+}{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance Improvements"},
+	{"revert", "Reverts"},
+	{"docs", "Documentation"},
+}
+
+// labelSections are Keep a Changelog (https://keepachangelog.com) change types, commits are labeled with
+// "Changelog: <label>" trailer e.g. "Changelog: fixed".
+var labelSections = []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}
+
+var labelTrailerRegex = regexp.MustCompile("(?mi)^Changelog:\\s*(\\w+)\\s*$")
+
+// versionHeadingRegex matches released version heading in Keep a Changelog file e.g. "## [1.2.0] - 2019-04-12"
+var versionHeadingRegex = regexp.MustCompile("(?m)^## \\[?(\\w[^\\]\\s]*)")
+
+const fileHeader = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.0.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+
+`
+
+type Entry struct {
+	Scope       string
+	Description string
+	Sha         string
+	Issues      []string
+}
+
+type Section struct {
+	Title   string
+	Entries []Entry
+}
+
+type Changelog struct {
+	Version  string
+	Date     time.Time
+	Sections []Section
+}
+
+// Generate collects commits since previous tag of s and groups them by conventional commit type
+// or by changelog label, depending on GOOPSC_CHANGELOG_GROUP_BY.
+func Generate(s semver.Semver, version string) (Changelog, error) {
+	setDefaults()
+	commits, err := s.GetCommits()
+	if err != nil {
+		return Changelog{}, err
+	}
+	c := Changelog{Version: version, Date: now()}
+	switch viper.GetString(GoopscChangelogGroupBy) {
+	case GroupByType:
+		c.Sections = groupByType(commits)
+	case GroupByLabel:
+		c.Sections = groupByLabel(commits)
+	default:
+		return Changelog{}, fmt.Errorf("unexpected %s: %s, expected one of: %s, %s", GoopscChangelogGroupBy, viper.GetString(GoopscChangelogGroupBy), GroupByType, GroupByLabel)
+	}
+	return c, nil
+}
+
+// ReleaseVersion returns version used in changelog heading: version of HEAD tag when HEAD is tagged,
+// e.g. in tag pipeline after release, otherwise next release version.
+func ReleaseVersion(s semver.Semver) (string, error) {
+	if version, _, tagged := s.GetHeadVersion(); tagged {
+		return version, nil
+	}
+	version, _, err := s.GetReleaseVersion("")
+	return version, err
+}
+
+func groupByType(commits []gitService.Commit) []Section {
+	entries := make(map[string][]Entry)
+	for _, commit := range commits {
+		cc, ok := semver.ParseConventionalCommit(commit.Message)
+		if !ok && isMergeCommit(commit) {
+			continue
+		}
+		entry := newEntry(commit, cc.Scope, cc.Description)
+		if cc.Breaking {
+			entries[breakingSection] = append(entries[breakingSection], entry)
+			continue
+		}
+		title := otherSection
+		for _, section := range typeSections {
+			if section.commitType == cc.Type {
+				title = section.title
+			}
+		}
+		entries[title] = append(entries[title], entry)
+	}
+	titles := []string{breakingSection}
+	for _, section := range typeSections {
+		titles = append(titles, section.title)
+	}
+	return sections(append(titles, otherSection), entries)
+}
+
+func groupByLabel(commits []gitService.Commit) []Section {
+	entries := make(map[string][]Entry)
+	for _, commit := range commits {
+		match := labelTrailerRegex.FindStringSubmatch(commit.Message)
+		if match == nil && isMergeCommit(commit) {
+			continue
+		}
+		title := otherSection
+		for _, section := range labelSections {
+			if match != nil && strings.EqualFold(section, match[1]) {
+				title = section
+			}
+		}
+		entries[title] = append(entries[title], newEntry(commit, "", commit.Subject()))
+	}
+	return sections(append(labelSections, otherSection), entries)
+}
+
+func sections(titles []string, entries map[string][]Entry) []Section {
+	result := make([]Section, 0)
+	for _, title := range titles {
+		if len(entries[title]) > 0 {
+			result = append(result, Section{Title: title, Entries: entries[title]})
+		}
+	}
+	return result
+}
+
+func newEntry(commit gitService.Commit, scope string, description string) Entry {
+	issues := jira.FilterIssueKeys(jira.ExtractIssueKeys(commit.Message))
+	return Entry{Scope: scope, Description: description, Sha: commit.Sha, Issues: issues}
+}
+
+func isMergeCommit(commit gitService.Commit) bool {
+	return strings.HasPrefix(commit.Subject(), "Merge ")
+}
+
+// Markdown renders changelog as Keep a Changelog version section.
+// Issue keys are linked to Jira when GOOPSC_JIRA_SERVER_URL is set.
+func (c Changelog) Markdown() string {
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("## [%s] - %s\n", c.Version, c.Date.Format("2006-01-02")))
+	for _, section := range c.Sections {
+		buffer.WriteString(fmt.Sprintf("\n### %s\n\n", section.Title))
+		for _, entry := range section.Entries {
+			buffer.WriteString("- ")
+			if entry.Scope != "" {
+				buffer.WriteString(fmt.Sprintf("**%s:** ", entry.Scope))
+			}
+			buffer.WriteString(entry.Description)
+			for _, issue := range entry.Issues {
+				buffer.WriteString(fmt.Sprintf(" %s", issueLink(issue)))
+			}
+			if len(entry.Sha) > 7 {
+				buffer.WriteString(fmt.Sprintf(" (%s)", entry.Sha[:7]))
+			}
+			buffer.WriteString("\n")
+		}
+	}
+	return buffer.String()
+}
+
+func issueLink(key string) string {
+	server := strings.TrimRight(viper.GetString(jira.GoopscJiraServerUrl), "/")
+	if server == "" {
+		return key
+	}
+	return fmt.Sprintf("[%s](%s/browse/%s)", key, server, key)
+}
+
+// UpdateFile inserts changelog above previously released versions in Keep a Changelog file at given path.
+// File is created when it does not exist.
+func UpdateFile(path string, c Changelog) error {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		content, err = []byte(fileHeader), nil
+	}
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(insertVersion(string(content), c.Markdown())), 0644)
+}
+
+// insertVersion inserts section before first released version, "Unreleased" section is kept on top.
+func insertVersion(content string, section string) string {
+	for _, match := range versionHeadingRegex.FindAllStringSubmatchIndex(content, -1) {
+		if strings.EqualFold(content[match[2]:match[3]], "Unreleased") {
+			continue
+		}
+		return content[:match[0]] + section + "\n" + content[match[0]:]
+	}
+	if content != "" && !strings.HasSuffix(content, "\n\n") {
+		content = strings.TrimRight(content, "\n") + "\n\n"
+	}
+	return content + section
+}
@@ -0,0 +1,150 @@
+package changelog
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/sotomskir/goops/features/jira"
+	"github.com/sotomskir/goops/features/semver"
+	"github.com/sotomskir/goops/gitService"
+	"github.com/sotomskir/goops/mockExecService"
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const commits = "1111111aaaa\x1ffeat(api): add login endpoint\n\nABC-1\n\x1e\n" +
+	"2222222bbbb\x1ffix: handle empty token ABC-2 ABC-2\n\nChangelog: fixed\n\x1e\n" +
+	"3333333cccc\x1fMerge branch 'feature' into master\n\x1e\n" +
+	"4444444dddd\x1frefactor!: rename config keys\n\nChangelog: changed\n\x1e\n" +
+	"5555555eeee\x1fupdate readme\n\x1e\n"
+
+func TestGenerate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now = func() time.Time { return time.Date(2019, 4, 12, 10, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+	viper.Set(semver.GoopscSemverStrategy, semver.GithubFlowStrategy)
+	viper.Set(jira.GoopscJiraServerUrl, "https://jira.example.com/")
+	defer viper.Set(jira.GoopscJiraServerUrl, "")
+	defer viper.Set(GoopscChangelogGroupBy, GroupByType)
+
+	tables := []struct {
+		groupBy  string
+		expected string
+	}{
+		{GroupByType, `## [1.3.0] - 2019-04-12
+
+### Breaking Changes
+
+- rename config keys (4444444)
+
+### Features
+
+- **api:** add login endpoint [ABC-1](https://jira.example.com/browse/ABC-1) (1111111)
+
+### Bug Fixes
+
+- handle empty token ABC-2 ABC-2 [ABC-2](https://jira.example.com/browse/ABC-2) (2222222)
+
+### Other Changes
+
+- update readme (5555555)
+`},
+		{GroupByLabel, `## [1.3.0] - 2019-04-12
+
+### Changed
+
+- refactor!: rename config keys (4444444)
+
+### Fixed
+
+- fix: handle empty token ABC-2 ABC-2 [ABC-2](https://jira.example.com/browse/ABC-2) (2222222)
+
+### Other Changes
+
+- feat(api): add login endpoint [ABC-1](https://jira.example.com/browse/ABC-1) (1111111)
+- update readme (5555555)
+`},
+	}
+
+	for _, table := range tables {
+		viper.Set(GoopscChangelogGroupBy, table.groupBy)
+		mockIService := mock_execService.NewMockIService(ctrl)
+		mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return("", nil).AnyTimes()
+		mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly").Return("1.2.0", nil).AnyTimes()
+		mockIService.EXPECT().Exec("git --no-pager log --format=%H%x1f%B%x1e 1.2.0..HEAD").Return(commits, nil)
+		gitService.Initialize(mockIService)
+		c, err := Generate(semver.New(), "1.3.0")
+		if err != nil {
+			t.Errorf("group by: %s, unexpected error: %s", table.groupBy, err)
+			continue
+		}
+		if actual := c.Markdown(); actual != table.expected {
+			t.Errorf("group by: %s, got:\n%s\nwant:\n%s", table.groupBy, actual, table.expected)
+		}
+	}
+}
+
+func TestReleaseVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	viper.Set(semver.GoopscSemverStrategy, semver.GithubFlowStrategy)
+	tables := []struct {
+		headTag  string
+		expected string
+	}{
+		{"1.2.0", "1.2.0"},
+		{"", "1.3.0"},
+	}
+
+	for _, table := range tables {
+		mockIService := mock_execService.NewMockIService(ctrl)
+		mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return(table.headTag, nil).AnyTimes()
+		mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly").Return("1.2.0", nil).AnyTimes()
+		gitService.Initialize(mockIService)
+		actual, err := ReleaseVersion(semver.New())
+		if err != nil || actual != table.expected {
+			t.Errorf("head tag: %s, got: %s, err: %v, want: %s", table.headTag, actual, err, table.expected)
+		}
+	}
+}
+
+func TestInsertVersion(t *testing.T) {
+	section := "## [1.3.0] - 2019-04-12\n\n### Features\n\n- login\n"
+	tables := []struct {
+		content  string
+		expected string
+	}{
+		{"", section},
+		{"# Changelog\n", "# Changelog\n\n" + section},
+		{"# Changelog\n\n## [1.2.0] - 2019-01-01\n", "# Changelog\n\n" + section + "\n## [1.2.0] - 2019-01-01\n"},
+		{"# Changelog\n\n## [Unreleased]\n\n## 1.2.0\n", "# Changelog\n\n## [Unreleased]\n\n" + section + "\n## 1.2.0\n"},
+	}
+
+	for _, table := range tables {
+		if actual := insertVersion(table.content, section); actual != table.expected {
+			t.Errorf("content: %q, got: %q, want: %q", table.content, actual, table.expected)
+		}
+	}
+}
+
+func TestUpdateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "changelog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "CHANGELOG.md")
+	c := Changelog{Version: "1.0.0", Date: time.Date(2019, 4, 12, 0, 0, 0, 0, time.UTC)}
+	if err := UpdateFile(path, c); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := ioutil.ReadFile(path)
+	if string(content) != fileHeader+"## [1.0.0] - 2019-04-12\n" {
+		t.Errorf("got: %q", content)
+	}
+}
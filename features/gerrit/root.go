@@ -0,0 +1,93 @@
+package gerrit
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/sotomskir/goops/gerritApi"
+	"github.com/spf13/viper"
+	"strconv"
+	"strings"
+)
+
+// GetRevision returns change and patch set revision built by Jenkins Gerrit Trigger,
+// current revision is returned when GERRIT_PATCHSET_REVISION is not set.
+func GetRevision() (string, string, error) {
+	change := viper.GetString("GERRIT_CHANGE_NUMBER")
+	if change == "" {
+		change = viper.GetString("GERRIT_CHANGE_ID")
+	}
+	if change == "" {
+		return "", "", errors.New("Gerrit change not found, GERRIT_CHANGE_NUMBER is not set")
+	}
+	revision := viper.GetString("GERRIT_PATCHSET_REVISION")
+	if revision == "" {
+		revision = "current"
+	}
+	return change, revision, nil
+}
+
+// ParseLabels parses votes in "Label=value" format e.g. "Verified=+1", "Code-Review=-1"
+func ParseLabels(labels []string) (map[string]int, error) {
+	result := make(map[string]int)
+	for _, label := range labels {
+		parts := strings.SplitN(label, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid label: '%s', expected format: Label=value e.g. Verified=+1", label)
+		}
+		value, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid label: '%s', vote must be a number e.g. Verified=-1", label)
+		}
+		result[strings.TrimSpace(parts[0])] = value
+	}
+	return result, nil
+}
+
+// ParseComments parses inline comments in "path:line:message" format, line 0 comments whole file
+func ParseComments(comments []string) (map[string][]gerritApi.CommentInput, error) {
+	result := make(map[string][]gerritApi.CommentInput)
+	for _, comment := range comments {
+		parts := strings.SplitN(comment, ":", 3)
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid comment: '%s', expected format: path:line:message", comment)
+		}
+		line, err := strconv.Atoi(parts[1])
+		if err != nil || line < 0 {
+			return nil, fmt.Errorf("invalid comment: '%s', line must be a non-negative number", comment)
+		}
+		result[parts[0]] = append(result[parts[0]], gerritApi.CommentInput{Line: line, Message: parts[2]})
+	}
+	return result, nil
+}
+
+// Review posts review on given revision of change, change and revision built by Gerrit Trigger are used when empty.
+func Review(change string, revision string, message string, labels []string, comments []string) error {
+	votes, err := ParseLabels(labels)
+	if err != nil {
+		return err
+	}
+	inline, err := ParseComments(comments)
+	if err != nil {
+		return err
+	}
+	if change == "" {
+		triggeredChange, triggeredRevision, err := GetRevision()
+		if err != nil {
+			return err
+		}
+		change = triggeredChange
+		if revision == "" {
+			revision = triggeredRevision
+		}
+	}
+	if revision == "" {
+		revision = "current"
+	}
+	if message == "" && len(votes) == 0 && len(inline) == 0 {
+		return errors.New("nothing to review, set message, label or comment")
+	}
+	logrus.Infof("Reviewing change: %s, revision: %s\n", change, revision)
+	gerritApi.SetReview(change, revision, gerritApi.ReviewInput{Message: message, Labels: votes, Comments: inline})
+	return nil
+}
@@ -0,0 +1,85 @@
+package gerrit
+
+import (
+	"fmt"
+	"github.com/sotomskir/goops/gerritApi"
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseLabels(t *testing.T) {
+	tables := []struct {
+		labels   []string
+		expected map[string]int
+		error    bool
+	}{
+		{[]string{"Verified=+1", "Code-Review=-1"}, map[string]int{"Verified": 1, "Code-Review": -1}, false},
+		{[]string{"Verified = 0"}, map[string]int{"Verified": 0}, false},
+		{[]string{"Verified+1"}, nil, true},
+		{[]string{"Verified=yes"}, nil, true},
+		{[]string{"=1"}, nil, true},
+	}
+
+	for _, table := range tables {
+		actual, err := ParseLabels(table.labels)
+		if (err != nil) != table.error || (!table.error && !reflect.DeepEqual(actual, table.expected)) {
+			t.Errorf("labels: %v, got: %v, err: %v, want: %v", table.labels, actual, err, table.expected)
+		}
+	}
+}
+
+func TestParseComments(t *testing.T) {
+	actual, err := ParseComments([]string{"main.go:12:Test failed: expected 1", "main.go:0:Not formatted", "README.md:3:Typo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]gerritApi.CommentInput{
+		"main.go":   {{Line: 12, Message: "Test failed: expected 1"}, {Line: 0, Message: "Not formatted"}},
+		"README.md": {{Line: 3, Message: "Typo"}},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("got: %v, want: %v", actual, expected)
+	}
+	for _, comment := range []string{"main.go:Typo", "main.go:x:Typo", ":1:Typo"} {
+		if _, err := ParseComments([]string{comment}); err == nil {
+			t.Errorf("comment: %s, expected error", comment)
+		}
+	}
+}
+
+func TestReview(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/changes/3/revisions/abc123/review" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		expected := `{"message":"Build successful","labels":{"Verified":1},"comments":{"main.go":[{"line":2,"message":"Typo"}]}}`
+		if string(body) != expected {
+			t.Errorf("got: %s, want: %s", body, expected)
+		}
+		fmt.Fprint(w, ")]}'\n{\"labels\":{\"Verified\":1}}")
+	}))
+	defer server.Close()
+	viper.Set("GOOPSC_GERRIT_URL", server.URL)
+	viper.Set("GERRIT_CHANGE_NUMBER", "3")
+	viper.Set("GERRIT_PATCHSET_REVISION", "abc123")
+	defer viper.Set("GOOPSC_GERRIT_URL", "")
+	defer viper.Set("GERRIT_CHANGE_NUMBER", "")
+	defer viper.Set("GERRIT_PATCHSET_REVISION", "")
+	gerritApi.Initialize()
+
+	if err := Review("", "", "Build successful", []string{"Verified=+1"}, []string{"main.go:2:Typo"}); err != nil {
+		t.Error(err)
+	}
+	if err := Review("", "", "", nil, nil); err == nil {
+		t.Errorf("expected error for empty review")
+	}
+	viper.Set("GERRIT_CHANGE_NUMBER", "")
+	if err := Review("", "", "Build successful", nil, nil); err == nil {
+		t.Errorf("expected error when change is not set")
+	}
+}
@@ -0,0 +1,33 @@
+package jira
+
+import (
+	"errors"
+	"github.com/sotomskir/goops/bitbucketApi"
+	"github.com/sotomskir/goops/features/bitbucket"
+)
+
+type bitbucketStrategy struct{}
+
+func (bitbucketStrategy) getTexts() ([]string, error) {
+	project, repo := bitbucketApi.GetRepository()
+	if project == "" || repo == "" {
+		return nil, errors.New("Bitbucket repository is not set, use GOOPSC_BITBUCKET_PROJECT and GOOPSC_BITBUCKET_REPOSITORY variables")
+	}
+	id, err := bitbucket.GetPullRequestId()
+	if err != nil {
+		return nil, err
+	}
+	pullRequest, err := bitbucketApi.GetPullRequest(project, repo, id)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := bitbucketApi.GetPullRequestCommits(project, repo, id)
+	if err != nil {
+		return nil, err
+	}
+	sources := []string{pullRequest.Title, pullRequest.Description, pullRequest.SourceBranch}
+	for _, commit := range commits {
+		sources = append(sources, commit.Message)
+	}
+	return sources, nil
+}
@@ -1,14 +1,67 @@
 package jira
 
 import (
+	"github.com/sotomskir/goops/gerritApi"
 	"github.com/sotomskir/goops/gitService"
+	"github.com/spf13/viper"
 	"regexp"
 )
 
+var changeIdFooterRegex = regexp.MustCompile("(?m)^Change-Id:\\s*(I[0-9a-f]{40})\\s*$")
+
 type gerritStrategy struct{}
 
-func (gerritStrategy) getIssues() []string {
-	msg := gitService.GetCommitMsg()
-	regex := regexp.MustCompile("(\\w+-\\d+)")
-	return regex.FindAllString(msg, 99)
+// getTexts collects commit messages from all patch sets of built change, changes of its topic and related changes.
+// Change is resolved from Gerrit Trigger variables or Change-Id footer of last commit.
+// Only last commit message is searched when GOOPSC_GERRIT_URL is not set or change can not be resolved.
+func (gerritStrategy) getTexts() ([]string, error) {
+	if !gerritApi.IsConfigured() {
+		return []string{gitService.GetCommitMsg()}, nil
+	}
+	changeId := getChangeId()
+	if changeId == "" {
+		msg := gitService.GetCommitMsg()
+		match := changeIdFooterRegex.FindStringSubmatch(msg)
+		if match == nil {
+			return []string{msg}, nil
+		}
+		changeId = match[1]
+	}
+	change, err := gerritApi.GetChange(changeId)
+	if err != nil {
+		return nil, err
+	}
+	sources := append([]string{change.Subject}, change.CommitMessages()...)
+	topic := change.Topic
+	if topic == "" {
+		topic = viper.GetString("GERRIT_TOPIC")
+	}
+	if topic != "" {
+		topicChanges, err := gerritApi.GetTopicChanges(topic)
+		if err != nil {
+			return nil, err
+		}
+		for _, topicChange := range topicChanges {
+			if topicChange.Number != change.Number {
+				sources = append(sources, topicChange.Subject)
+				sources = append(sources, topicChange.CommitMessages()...)
+			}
+		}
+	}
+	relatedChanges, err := gerritApi.GetRelatedChanges(changeId)
+	if err != nil {
+		return nil, err
+	}
+	for _, related := range relatedChanges {
+		sources = append(sources, related.Commit.Subject)
+	}
+	return sources, nil
+}
+
+// getChangeId returns change built by Gerrit Trigger, empty string is returned when build is not triggered by Gerrit.
+func getChangeId() string {
+	if number := viper.GetString("GERRIT_CHANGE_NUMBER"); number != "" {
+		return number
+	}
+	return viper.GetString("GERRIT_CHANGE_ID")
 }
@@ -0,0 +1,81 @@
+package jira
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"github.com/sotomskir/goops/gitService"
+	"github.com/sotomskir/goops/githubApi"
+	"github.com/spf13/viper"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+var pullRequestRefRegex = regexp.MustCompile("^refs/pull/(\\d+)/")
+
+type githubStrategy struct{}
+
+func (githubStrategy) getTexts() ([]string, error) {
+	repo := githubApi.GetRepository()
+	if repo == "" {
+		return nil, errors.New("GitHub repository is not set, use GOOPSC_GITHUB_REPOSITORY variable")
+	}
+	number, err := getPullRequestNumber()
+	if err != nil {
+		return nil, err
+	}
+	pullRequest, err := githubApi.GetPullRequest(repo, number)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := githubApi.GetPullRequestCommits(repo, number)
+	if err != nil {
+		return nil, err
+	}
+	sources := []string{pullRequest.Title, pullRequest.Body, pullRequest.Head.Ref}
+	for _, commit := range commits {
+		sources = append(sources, commit.Commit.Message)
+	}
+	return sources, nil
+}
+
+// getPullRequestNumber returns number of pull request built by GitHub Actions or Travis CI,
+// or number of previously merged pull request when build is not triggered by pull request.
+func getPullRequestNumber() (int, error) {
+	if number := pullRequestNumberFromEvent(viper.GetString("GITHUB_EVENT_PATH")); number > 0 {
+		return number, nil
+	}
+	if match := pullRequestRefRegex.FindStringSubmatch(viper.GetString("GITHUB_REF")); match != nil {
+		number, _ := strconv.Atoi(match[1])
+		return number, nil
+	}
+	if number, err := strconv.Atoi(viper.GetString("TRAVIS_PULL_REQUEST")); err == nil {
+		return number, nil
+	}
+	return gitService.GetPreviousPullRequestNumber()
+}
+
+// pullRequestNumberFromEvent reads pull request number from GitHub Actions event payload, 0 is returned
+// when event is not related to pull request.
+func pullRequestNumberFromEvent(path string) int {
+	if path == "" {
+		return 0
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		logrus.Debugln(err)
+		return 0
+	}
+	defer f.Close()
+	event := struct {
+		PullRequest struct {
+			Number int `json:"number"`
+		} `json:"pull_request"`
+	}{}
+	if err := json.NewDecoder(f).Decode(&event); err != nil {
+		logrus.Debugln(err)
+		return 0
+	}
+	return event.PullRequest.Number
+}
@@ -1,29 +1,60 @@
 package jira
 
 import (
+	"errors"
 	"github.com/sirupsen/logrus"
 	"github.com/sotomskir/goops/gitService"
 	"github.com/sotomskir/goops/gitlabApi"
 	"github.com/spf13/viper"
+	"strconv"
 )
 
 type gitlabStrategy struct{}
 
-func (gitlabStrategy) getIssues() []string {
-	mergeRequestIid := getMergeRequestIid()
-	mergeRequestIid = "3"
+func (gitlabStrategy) getTexts() ([]string, error) {
 	projectId := viper.GetString("CI_PROJECT_ID")
 	if projectId == "" {
-		logrus.Fatalln("CI_PROJECT_ID is not set")
+		return nil, errors.New("CI_PROJECT_ID is not set")
 	}
-	issueKeys := gitlabApi.GetMergeRequestIssueKeys(projectId, mergeRequestIid)
-	return issueKeys
+	mergeRequestIid, err := getMergeRequestIid(projectId)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Merge request: !%s\n", mergeRequestIid)
+	return gitlabApi.GetMergeRequestTexts(projectId, mergeRequestIid)
 }
 
-func getMergeRequestIid() string {
-	iid := viper.GetString("CI_MERGE_REQUEST_IID")
-	if iid == "" {
-		iid = gitService.GetPreviousMergeRequestIid()
+// getMergeRequestIid returns merge request iid from CI_MERGE_REQUEST_IID variable or --mr flag,
+// from previous merge commit message, or from merge requests associated with HEAD commit
+// when merge commit does not reference merge request e.g. for squash merges.
+func getMergeRequestIid(projectId string) (string, error) {
+	if iid := viper.GetString("CI_MERGE_REQUEST_IID"); iid != "" {
+		return iid, nil
+	}
+	iid, found, err := gitService.GetPreviousMergeRequestIid()
+	if err != nil {
+		return "", err
+	}
+	if found {
+		return iid, nil
+	}
+	sha := viper.GetString("CI_COMMIT_SHA")
+	if sha == "" {
+		if sha, err = gitService.GetHeadSha(); err != nil {
+			return "", err
+		}
+	}
+	mergeRequests, err := gitlabApi.GetCommitMergeRequests(projectId, sha)
+	if err != nil {
+		return "", err
+	}
+	for _, mergeRequest := range mergeRequests {
+		if mergeRequest.State == "merged" {
+			return strconv.Itoa(mergeRequest.Iid), nil
+		}
+	}
+	if len(mergeRequests) > 0 {
+		return strconv.Itoa(mergeRequests[0].Iid), nil
 	}
-	return iid
+	return "", errors.New("merge request not found, set CI_MERGE_REQUEST_IID or use --mr flag")
 }
@@ -0,0 +1,110 @@
+package jira
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/sotomskir/goops/jiraApi"
+	"github.com/spf13/viper"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// defaultIssuePattern matches whole words in default Jira issue key format e.g. ABC-123
+const defaultIssuePattern = "\\b[A-Z][A-Z0-9_]+-[0-9]+\\b"
+
+// ExtractIssueKeys returns issue keys matching GOOPSC_JIRA_ISSUE_PATTERN found in given texts,
+// without duplicates, in order of appearance.
+func ExtractIssueKeys(texts ...string) []string {
+	setDefaults()
+	regex, err := regexp.Compile(viper.GetString(GoopscJiraIssuePattern))
+	if err != nil {
+		logrus.Fatalf("invalid %s: %s\n", GoopscJiraIssuePattern, err)
+	}
+	keys := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, text := range texts {
+		for _, key := range regex.FindAllString(text, -1) {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// FilterIssueKeys returns keys of projects listed in GOOPSC_JIRA_PROJECT_KEY. All projects are accepted when
+// GOOPSC_JIRA_PROJECT_KEY is empty. Keys of projects that do not exist in Jira, e.g. UTF-8, are dropped
+// by validateProjects before Jira issues are updated.
+func FilterIssueKeys(keys []string) []string {
+	projects := ProjectKeys()
+	filtered := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if isProjectKey(key, projects) {
+			filtered = append(filtered, key)
+		}
+	}
+	return filtered
+}
+
+// ProjectKeys returns Jira project keys configured in GOOPSC_JIRA_PROJECT_KEY,
+// separated by comma or whitespace, or given as YAML list.
+func ProjectKeys() []string {
+	keys := make([]string, 0)
+	for _, value := range viper.GetStringSlice(GoopscJiraProjectKey) {
+		keys = append(keys, strings.FieldsFunc(value, func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})...)
+	}
+	return keys
+}
+
+func isProjectKey(key string, projects []string) bool {
+	if len(projects) == 0 {
+		return true
+	}
+	index := strings.LastIndex(key, "-")
+	if index < 0 {
+		return false
+	}
+	project := key[:index]
+	for _, p := range projects {
+		if strings.EqualFold(project, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateProjects drops keys of projects that do not exist in Jira, projects list is read once.
+func validateProjects(keys []string) ([]string, error) {
+	if len(keys) == 0 {
+		return keys, nil
+	}
+	projects, err := getJiraProjects()
+	if err != nil {
+		return nil, err
+	}
+	valid := make([]string, 0)
+	for _, key := range keys {
+		if isProjectKey(key, projects) {
+			valid = append(valid, key)
+		} else {
+			logrus.Warnf("Project of issue: %s does not exist in Jira, issue is skipped\n", key)
+		}
+	}
+	return valid, nil
+}
+
+func getJiraProjects() ([]string, error) {
+	jiraInitApi()
+	response, err := jiraApi.GetProjects()
+	if err != nil {
+		return nil, err
+	}
+	projects := make([]string, 0, len(response))
+	for _, project := range response {
+		projects = append(projects, project.Key)
+	}
+	return projects, nil
+}
@@ -0,0 +1,145 @@
+package jira
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/sotomskir/goops/jiraApi"
+	"github.com/sotomskir/goops/utils"
+	"github.com/spf13/viper"
+	"strings"
+	"time"
+)
+
+const (
+	// Configuration variables
+	GoopscJiraReleaseUnresolved = "GOOPSC_JIRA_RELEASE_UNRESOLVED"
+
+	// Configuration options
+	UnresolvedFail   = "fail"
+	UnresolvedWarn   = "warn"
+	UnresolvedIgnore = "ignore"
+)
+
+// ReleaseOptions configures ReleaseVersion
+type ReleaseOptions struct {
+	// Projects where version is released, GOOPSC_JIRA_PROJECT_KEY projects are used when empty
+	Projects []string
+	// Date is release date, today when zero
+	Date time.Time
+	// MoveUnresolvedTo is version where issues not in done status are moved before release
+	MoveUnresolvedTo string
+	// Unresolved is one of fail, warn, ignore. GOOPSC_JIRA_RELEASE_UNRESOLVED is used when empty
+	Unresolved string
+}
+
+// ReleaseVersion marks version as released in Jira projects. Issues of version that are not in done status
+// are moved to options.MoveUnresolvedTo version when set, otherwise release fails or warns about them.
+func (o *Jira) ReleaseVersion(version string, options ReleaseOptions) error {
+	if utils.IsDisabled(GoopscJira) {
+		return nil
+	}
+	viper.SetDefault(GoopscJiraReleaseUnresolved, UnresolvedFail)
+	if options.Unresolved == "" {
+		options.Unresolved = viper.GetString(GoopscJiraReleaseUnresolved)
+	}
+	if options.Unresolved != UnresolvedFail && options.Unresolved != UnresolvedWarn && options.Unresolved != UnresolvedIgnore {
+		return fmt.Errorf("unexpected unresolved issues handling: %s, expected one of: %s, %s, %s", options.Unresolved, UnresolvedFail, UnresolvedWarn, UnresolvedIgnore)
+	}
+	if len(options.Projects) == 0 {
+		options.Projects = ProjectKeys()
+	}
+	if len(options.Projects) == 0 {
+		return fmt.Errorf("Jira project is not set, use %s variable", GoopscJiraProjectKey)
+	}
+	if version == "" {
+		return errors.New("version is empty")
+	}
+	if options.Date.IsZero() {
+		options.Date = time.Now()
+	}
+	jiraInitApi()
+	for _, project := range options.Projects {
+		if err := releaseProjectVersion(project, version, options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func releaseProjectVersion(project string, version string, options ReleaseOptions) error {
+	v, found, err := jiraApi.GetVersion(project, version)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("version: %s does not exist in project: %s", version, project)
+	}
+	if v.IsReleased() {
+		logrus.Infof("Version: %s is already released in project: %s\n", version, project)
+		return nil
+	}
+	unresolved, err := getUnresolvedIssues(project, version)
+	if err != nil {
+		return err
+	}
+	if len(unresolved) > 0 && options.MoveUnresolvedTo != "" {
+		if err := moveIssues(project, unresolved, version, options.MoveUnresolvedTo); err != nil {
+			return err
+		}
+		unresolved = nil
+	}
+	if len(unresolved) > 0 {
+		msg := fmt.Sprintf("issues of version: %s in project: %s are not done: %s", version, project, strings.Join(unresolved, ", "))
+		switch options.Unresolved {
+		case UnresolvedFail:
+			return errors.New(msg)
+		case UnresolvedWarn:
+			logrus.Warnln(msg)
+		}
+	}
+	released := true
+	logrus.Infof("Release version: %s in project: %s\n", version, project)
+	_, err = jiraApi.UpdateVersion(v.Id, jiraApi.Version{Released: &released, ReleaseDate: options.Date.Format("2006-01-02")})
+	return err
+}
+
+// getUnresolvedIssues returns keys of version issues that are not in status of done category
+func getUnresolvedIssues(project string, version string) ([]string, error) {
+	issues, err := jiraApi.SearchIssues(fmt.Sprintf(`project = "%s" AND fixVersion = "%s"`, project, version))
+	if err != nil {
+		return nil, err
+	}
+	unresolved := make([]string, 0)
+	for _, issue := range issues {
+		if issue.Fields.Status.StatusCategory.Key != "done" {
+			unresolved = append(unresolved, issue.Key)
+		}
+	}
+	return unresolved, nil
+}
+
+// moveIssues moves issues from version to next version, next version is created when it does not exist
+// and GOOPSC_JIRA_VERSION_CREATE is enabled.
+func moveIssues(project string, issues []string, version string, next string) error {
+	_, found, err := jiraApi.GetVersion(project, next)
+	if err != nil {
+		return err
+	}
+	if !found {
+		if utils.IsDisabled(GoopscJiraVersionCreate) {
+			return fmt.Errorf("version: %s does not exist in project: %s", next, project)
+		}
+		logrus.Infof("Create version: %s in project: %s\n", next, project)
+		if _, err := jiraApi.CreateVersion(project, next); err != nil {
+			return err
+		}
+	}
+	for _, issue := range issues {
+		logrus.Infof("Move issue: %s from version: %s to version: %s\n", issue, version, next)
+		if err := jiraApi.MoveFixVersion(issue, version, next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
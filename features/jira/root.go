@@ -3,8 +3,9 @@ package jira
 import (
 	"fmt"
 	"github.com/sirupsen/logrus"
+	"github.com/sotomskir/goops/features/semver"
+	"github.com/sotomskir/goops/jiraApi"
 	"github.com/sotomskir/goops/utils"
-	"github.com/sotomskir/jira-cli/jiraApi"
 	"github.com/spf13/viper"
 	"strings"
 )
@@ -23,22 +24,32 @@ const (
 	GoopscJiraWorkflow              = "GOOPSC_JIRA_WORKFLOW"
 	GoopscJiraWorkflowContent       = "GOOPSC_JIRA_WORKFLOW_CONTENT"
 	GoopscJiraStrategy              = "GOOPSC_JIRA_STRATEGY"
+	GoopscJiraIssuePattern          = "GOOPSC_JIRA_ISSUE_PATTERN"
+	GoopscJiraProjectValidate       = "GOOPSC_JIRA_PROJECT_VALIDATE"
+	GoopscJiraIssueScope            = "GOOPSC_JIRA_ISSUE_SCOPE"
 
 	// Output variables
 	GoopsJiraIssues = "GOOPS_JIRA_ISSUES"
 
 	// Configuration options
-	GerritStrategy = "gerrit"
-	GitlabStrategy = "gitlab"
+	GerritStrategy    = "gerrit"
+	GitlabStrategy    = "gitlab"
+	GithubStrategy    = "github"
+	BitbucketStrategy = "bitbucket"
+	ChangeScope       = "change"
+	ReleaseScope      = "release"
 )
 
 func setDefaults() {
 	viper.SetDefault(GoopscJira, "false")
 	viper.SetDefault(GoopscJiraStrategy, GerritStrategy)
+	viper.SetDefault(GoopscJiraIssuePattern, defaultIssuePattern)
+	viper.SetDefault(GoopscJiraProjectValidate, "false")
+	viper.SetDefault(GoopscJiraIssueScope, ChangeScope)
 }
 
 type strategy interface {
-	getIssues() []string
+	getTexts() ([]string, error)
 }
 
 type Jira struct {
@@ -55,9 +66,18 @@ func New() Jira {
 	case GitlabStrategy:
 		strategy = gitlabStrategy{}
 		break
+	case GithubStrategy:
+		strategy = githubStrategy{}
+		break
+	case BitbucketStrategy:
+		strategy = bitbucketStrategy{}
+		break
 	default:
 		panic(fmt.Sprintf("unsupported strategy: %s\n", viper.GetString(GoopscJiraStrategy)))
 	}
+	if scope := viper.GetString(GoopscJiraIssueScope); scope != ChangeScope && scope != ReleaseScope {
+		panic(fmt.Sprintf("unsupported issue scope: %s\n", scope))
+	}
 	return Jira{strategy: strategy}
 }
 
@@ -65,21 +85,79 @@ func (o *Jira) GetIssues() []string {
 	if utils.IsDisabled(GoopscJira) {
 		return nil
 	}
-	issues := o.strategy.getIssues()
+	var texts []string
+	if viper.GetString(GoopscJiraIssueScope) == ReleaseScope {
+		texts = o.getReleaseTexts()
+	} else {
+		var err error
+		if texts, err = o.strategy.getTexts(); err != nil {
+			logrus.Fatalln(err)
+		}
+	}
+	issues := FilterIssueKeys(ExtractIssueKeys(texts...))
+	if utils.IsEnabled(GoopscJiraProjectValidate) {
+		var err error
+		if issues, err = validateProjects(issues); err != nil {
+			logrus.Fatalln(err)
+		}
+	}
 	issueKeysJoined := strings.Join(issues, " ")
 	utils.SaveExportString(GoopsJiraIssues, issueKeysJoined)
 	return issues
 }
 
-func (o *Jira) JiraTransition(issues string, state string) {
+// getReleaseTexts returns messages of all commits since previous release tag,
+// merged with texts found by strategy when merge request or pull request can be resolved.
+func (o *Jira) getReleaseTexts() []string {
+	s := semver.New()
+	commits, err := s.GetCommits()
+	if err != nil {
+		logrus.Fatalln(err)
+	}
+	logrus.Debugf("Searching issues in %d commits since previous release\n", len(commits))
+	texts := make([]string, 0)
+	for _, commit := range commits {
+		texts = append(texts, commit.Message)
+	}
+	strategyTexts, err := o.strategy.getTexts()
+	if err != nil {
+		logrus.Warnf("%s, only commits since previous release are searched\n", err)
+	}
+	return append(texts, strategyTexts...)
+}
+
+// JiraTransition moves issues to state following shortest path of transitions in workflow,
+// issues are transitioned directly when workflow is not defined. Error lists issues that did not reach state.
+func (o *Jira) JiraTransition(issues string, state string) error {
 	if utils.IsDisabled(GoopscJira) || utils.IsDisabled(GoopscJiraIssueTransition) {
-		return
+		return nil
+	}
+	workflow, err := LoadWorkflow()
+	if err != nil {
+		return err
 	}
 	jiraInitApi()
-	for _, issue := range strings.Split(issues, " ") {
+	keys, err := validateProjects(strings.Fields(issues))
+	if err != nil {
+		return err
+	}
+	failed := make([]string, 0)
+	for _, issue := range keys {
 		logrus.Infof("Transition issue: %s to state: %s\n", issue, state)
-		jiraApi.TransitionIssue("", issue, state, "")
+		if workflow != nil {
+			err = workflow.TransitionIssue(issue, state)
+		} else {
+			err = jiraApi.TransitionIssue(issue, state)
+		}
+		if err != nil {
+			logrus.Errorln(err)
+			failed = append(failed, issue)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("issues not transitioned to state: %s: %s", state, strings.Join(failed, ", "))
 	}
+	return nil
 }
 
 func (o *Jira) SetJiraVersion(version string, issues []string, summary string, description string, issueType string) {
@@ -87,23 +165,78 @@ func (o *Jira) SetJiraVersion(version string, issues []string, summary string, d
 		return
 	}
 	jiraInitApi()
+	issues, err := validateProjects(issues)
+	if err != nil {
+		logrus.Errorln(err)
+		return
+	}
+	// version is resolved once per project, not for every issue
+	projects := make(map[string]error)
 	for _, issue := range issues {
 		logrus.Infof("Set version: %s for issue: %s\n", version, issue)
-		err := jiraApi.AssignVersion(
-			issue,
-			version,
-			utils.IsEnabled(GoopscJiraVersionCreate),
-			utils.IsEnabled(GoopscJiraCreateDeploymentIssue),
-			summary,
-			description,
-			issueType)
-		if err != nil {
+		if err := assignVersion(issue, version, summary, description, issueType, projects); err != nil {
 			logrus.Errorln(err)
 		}
 	}
 }
 
+// assignVersion adds version to fix versions of issue. Version is resolved in project of issue unless projects
+// already holds result of resolving it there.
+func assignVersion(issueKey string, version string, summary string, description string, issueType string, projects map[string]error) error {
+	issue, err := jiraApi.GetIssue(issueKey)
+	if err != nil {
+		return err
+	}
+	project := issue.Fields.Project.Key
+	err, resolved := projects[project]
+	if !resolved {
+		err = ensureVersion(project, version, summary, description, issueType)
+		projects[project] = err
+	}
+	if err != nil {
+		return err
+	}
+	return jiraApi.AddFixVersion(issueKey, version)
+}
+
+// ensureVersion creates missing version in project when GOOPSC_JIRA_VERSION_CREATE is enabled,
+// together with deployment issue when GOOPSC_JIRA_CREATE_DEPLOYMENT_ISSUE is enabled.
+func ensureVersion(project string, version string, summary string, description string, issueType string) error {
+	_, found, err := jiraApi.GetVersion(project, version)
+	if err != nil || found {
+		return err
+	}
+	if utils.IsDisabled(GoopscJiraVersionCreate) {
+		return fmt.Errorf("version: %s does not exist in project: %s", version, project)
+	}
+	logrus.Infof("Create version: %s in project: %s\n", version, project)
+	if _, err := jiraApi.CreateVersion(project, version); err != nil {
+		return err
+	}
+	if utils.IsEnabled(GoopscJiraCreateDeploymentIssue) {
+		// version exists at this point, so issues can be assigned even when deployment issue is not created
+		if err := createDeploymentIssue(project, version, summary, description, issueType); err != nil {
+			logrus.Errorln(err)
+		}
+	}
+	return nil
+}
+
+func createDeploymentIssue(project string, version string, summary string, description string, issueType string) error {
+	if summary == "" {
+		summary = fmt.Sprintf("Deployment of version %s", version)
+	}
+	if issueType == "" {
+		issueType = "Task"
+	}
+	issue, err := jiraApi.CreateIssue(project, issueType, summary, description, version)
+	if err == nil {
+		logrus.Infof("Created deployment issue: %s\n", issue.Key)
+	}
+	return err
+}
+
 func jiraInitApi() {
-	utils.ViperValidateEnv(GoopscJiraServerUrl, GoopscJiraUser, GoopscJiraPassword)
-	jiraApi.Initialize(viper.GetString(GoopscJiraServerUrl), viper.GetString(GoopscJiraUser), viper.GetString(GoopscJiraPassword))
+	utils.ViperValidateEnv(GoopscJiraServerUrl)
+	jiraApi.Initialize()
 }
@@ -1,12 +1,25 @@
 package jira
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/golang/mock/gomock"
+	"github.com/sotomskir/goops/bitbucketApi"
+	"github.com/sotomskir/goops/gerritApi"
 	"github.com/sotomskir/goops/gitService"
+	"github.com/sotomskir/goops/githubApi"
+	"github.com/sotomskir/goops/gitlabApi"
+	"github.com/sotomskir/goops/jiraApi"
 	"github.com/sotomskir/goops/mockExecService"
 	"github.com/spf13/viper"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetIssues(t *testing.T) {
@@ -32,3 +45,597 @@ func TestGetIssues(t *testing.T) {
 		}
 	}
 }
+
+func TestGetIssuesGithub(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/app/pulls/7":
+			fmt.Fprint(w, `{"number":7,"title":"ABC-1 Login","body":"Fixes ABC-2","head":{"ref":"feature/ABC-3-login"}}`)
+		case "/repos/owner/app/pulls/7/commits":
+			fmt.Fprint(w, `[{"sha":"1","commit":{"message":"ABC-1 add form"}},{"sha":"2","commit":{"message":"ABC-4 fix test"}}]`)
+		default:
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+	}))
+	defer server.Close()
+	viper.Set("GOOPSC_GITHUB_API_URL", server.URL)
+	viper.Set("GOOPSC_GITHUB_TOKEN", "secret")
+	viper.Set("GOOPSC_GITHUB_REPOSITORY", "owner/app")
+	viper.Set("GITHUB_REF", "refs/pull/7/merge")
+	viper.Set(GoopscJira, "true")
+	viper.Set(GoopscJiraStrategy, GithubStrategy)
+	defer viper.Set("GOOPSC_GITHUB_REPOSITORY", "")
+	defer viper.Set("GITHUB_REF", "")
+	defer viper.Set(GoopscJiraStrategy, GerritStrategy)
+	githubApi.Initialize()
+
+	j := New()
+	actual := strings.Join(j.GetIssues(), " ")
+	if actual != "ABC-1 ABC-2 ABC-3 ABC-4" {
+		t.Errorf("got: '%s', want: '%s'", actual, "ABC-1 ABC-2 ABC-3 ABC-4")
+	}
+}
+
+func TestGetPullRequestNumber(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dir, err := ioutil.TempDir("", "event")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	pullRequestEvent := filepath.Join(dir, "pull_request.json")
+	ioutil.WriteFile(pullRequestEvent, []byte(`{"action":"opened","number":5,"pull_request":{"number":5}}`), 0644)
+	pushEvent := filepath.Join(dir, "push.json")
+	ioutil.WriteFile(pushEvent, []byte(`{"ref":"refs/heads/master"}`), 0644)
+
+	tables := []struct {
+		eventPath string
+		ref       string
+		travis    string
+		expected  int
+	}{
+		{pullRequestEvent, "refs/pull/6/merge", "", 5},
+		{pushEvent, "refs/pull/6/merge", "", 6},
+		{"", "refs/heads/master", "8", 8},
+		{pushEvent, "refs/heads/master", "false", 9},
+	}
+
+	defer viper.Set("GITHUB_EVENT_PATH", "")
+	defer viper.Set("GITHUB_REF", "")
+	defer viper.Set("TRAVIS_PULL_REQUEST", "")
+	for _, table := range tables {
+		viper.Set("GITHUB_EVENT_PATH", table.eventPath)
+		viper.Set("GITHUB_REF", table.ref)
+		viper.Set("TRAVIS_PULL_REQUEST", table.travis)
+		mockIService := mock_execService.NewMockIService(ctrl)
+		mockIService.EXPECT().Exec("git --no-pager log -1 --merges").Return("Merge pull request #9 from owner/feature", nil).AnyTimes()
+		gitService.Initialize(mockIService)
+		if actual, err := getPullRequestNumber(); err != nil || actual != table.expected {
+			t.Errorf("got: %d, err: %v, want: %d, %v", actual, err, table.expected, table)
+		}
+	}
+}
+
+func TestGetIssuesBitbucket(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repositories/team/app/pullrequests/3":
+			fmt.Fprint(w, `{"id":3,"title":"ABC-1 Login","description":"Fixes ABC-2","source":{"branch":{"name":"feature/ABC-3-login"}}}`)
+		case "/repositories/team/app/pullrequests/3/commits":
+			fmt.Fprint(w, `{"values":[{"hash":"1","message":"ABC-1 add form"},{"hash":"2","message":"ABC-4 fix test"}]}`)
+		default:
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+	}))
+	defer server.Close()
+	viper.Set("GOOPSC_BITBUCKET_URL", server.URL)
+	viper.Set("GOOPSC_BITBUCKET_TOKEN", "token")
+	viper.Set("BITBUCKET_WORKSPACE", "team")
+	viper.Set("BITBUCKET_REPO_SLUG", "app")
+	viper.Set("BITBUCKET_PR_ID", "3")
+	viper.Set(GoopscJira, "true")
+	viper.Set(GoopscJiraStrategy, BitbucketStrategy)
+	defer viper.Set("BITBUCKET_PR_ID", "")
+	defer viper.Set("GOOPSC_BITBUCKET_TOKEN", "")
+	defer viper.Set(GoopscJiraStrategy, GerritStrategy)
+	bitbucketApi.Initialize()
+
+	j := New()
+	actual := strings.Join(j.GetIssues(), " ")
+	if actual != "ABC-1 ABC-2 ABC-3 ABC-4" {
+		t.Errorf("got: '%s', want: '%s'", actual, "ABC-1 ABC-2 ABC-3 ABC-4")
+	}
+}
+
+func TestGetIssuesGerrit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/changes/I0123456789abcdef0123456789abcdef01234567/detail":
+			fmt.Fprint(w, `)]}'
+{"subject":"ABC-1 Login","topic":"login","_number":3,"revisions":{"a1":{"_number":1,"commit":{"message":"ABC-1 Login"}},"b2":{"_number":2,"commit":{"message":"ABC-1 Login\n\nABC-2"}}}}`)
+		case "/changes/":
+			fmt.Fprint(w, `)]}'
+[{"subject":"ABC-1 Login","_number":3},{"subject":"ABC-3 Api","_number":5,"revisions":{"c3":{"_number":1,"commit":{"message":"ABC-3 Api"}}}}]`)
+		case "/changes/I0123456789abcdef0123456789abcdef01234567/revisions/current/related":
+			fmt.Fprint(w, `)]}'
+{"changes":[{"commit":{"subject":"ABC-4 Form"},"_change_number":4}]}`)
+		default:
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+	}))
+	defer server.Close()
+	mockIService := mock_execService.NewMockIService(ctrl)
+	mockIService.EXPECT().Exec("git --no-pager log -1 --pretty=%B").Return("ABC-1 Login\n\nChange-Id: I0123456789abcdef0123456789abcdef01234567\n", nil)
+	gitService.Initialize(mockIService)
+	viper.Set("GOOPSC_GERRIT_URL", server.URL)
+	viper.Set(GoopscJira, "true")
+	defer viper.Set("GOOPSC_GERRIT_URL", "")
+	gerritApi.Initialize()
+
+	j := New()
+	actual := strings.Join(j.GetIssues(), " ")
+	if actual != "ABC-1 ABC-2 ABC-3 ABC-4" {
+		t.Errorf("got: '%s', want: '%s'", actual, "ABC-1 ABC-2 ABC-3 ABC-4")
+	}
+}
+
+func TestGetMergeRequestIid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/projects/42/repository/commits/abc/merge_requests":
+			fmt.Fprint(w, `[{"iid":7,"state":"opened"},{"iid":8,"state":"merged"}]`)
+		case "/projects/42/repository/commits/def/merge_requests":
+			fmt.Fprint(w, `[]`)
+		default:
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+	}))
+	defer server.Close()
+	viper.Set("ci_api_v4_url", server.URL)
+	viper.Set("ci_gitlab_token", "secret")
+	gitlabApi.Initialize()
+
+	tables := []struct {
+		iid      string
+		merge    string
+		sha      string
+		expected string
+		error    bool
+	}{
+		{"4", "", "", "4", false},
+		{"", "Merge branch 'feature' into 'master'\n\nSee merge request group/app!5", "", "5", false},
+		{"", "", "abc", "8", false},
+		{"", "Merge branch 'feature' into 'master'", "def", "", true},
+	}
+
+	defer viper.Set("CI_MERGE_REQUEST_IID", "")
+	for _, table := range tables {
+		viper.Set("CI_MERGE_REQUEST_IID", table.iid)
+		mockIService := mock_execService.NewMockIService(ctrl)
+		mockIService.EXPECT().Exec("git --no-pager log -1 --merges").Return(table.merge, nil).AnyTimes()
+		mockIService.EXPECT().Exec("git rev-parse HEAD").Return(table.sha, nil).AnyTimes()
+		gitService.Initialize(mockIService)
+		actual, err := getMergeRequestIid("42")
+		if actual != table.expected || (err != nil) != table.error {
+			t.Errorf("got: %s, err: %v, want: %s, %v", actual, err, table.expected, table)
+		}
+	}
+}
+
+func TestExtractIssueKeys(t *testing.T) {
+	defer viper.Set(GoopscJiraIssuePattern, defaultIssuePattern)
+	viper.Set(GoopscJiraIssuePattern, "\\w+-\\d+")
+	keys := ExtractIssueKeys("Some merge request title related to TEST-1 and Test-2312 issues", "TEST-1 again")
+	if strings.Join(keys, " ") != "TEST-1 Test-2312" {
+		t.Errorf("Keys was incorrect, got: %#v, want: %#v\n", keys, []string{"TEST-1", "Test-2312"})
+	}
+}
+
+func TestFilterIssueKeys(t *testing.T) {
+	texts := []string{
+		"ABC-1 add UTF-8 support",
+		"feature_DEF-2 update log4j-2 and use SHA-256",
+		"Refs: ABC-1, Test-3, XYZ-4",
+		"XABC-123abc feature/DEF-5-login",
+	}
+	tables := []struct {
+		projects string
+		pattern  string
+		expected string
+	}{
+		{"", "", "ABC-1 UTF-8 SHA-256 XYZ-4 DEF-5"},
+		{"ABC,DEF", "", "ABC-1 DEF-5"},
+		{"abc xyz", "", "ABC-1 XYZ-4"},
+		{"", "(ABC|Test)-\\d+", "ABC-1 Test-3 ABC-123"},
+		{"", "\\b(ABC|Test)-\\d+\\b", "ABC-1 Test-3"},
+		{"", "Test-\\d+", "Test-3"},
+		{"ABC", "\\d+", ""},
+	}
+
+	defer viper.Set(GoopscJiraProjectKey, "")
+	defer viper.Set(GoopscJiraIssuePattern, defaultIssuePattern)
+	for _, table := range tables {
+		viper.Set(GoopscJiraProjectKey, table.projects)
+		viper.Set(GoopscJiraIssuePattern, defaultIssuePattern)
+		if table.pattern != "" {
+			viper.Set(GoopscJiraIssuePattern, table.pattern)
+		}
+		actual := strings.Join(FilterIssueKeys(ExtractIssueKeys(texts...)), " ")
+		if actual != table.expected {
+			t.Errorf("projects: '%s', pattern: '%s', got: '%s', want: '%s'", table.projects, table.pattern, actual, table.expected)
+		}
+	}
+}
+
+func TestProjectKeys(t *testing.T) {
+	defer viper.Set(GoopscJiraProjectKey, "")
+	viper.Set(GoopscJiraProjectKey, []string{"ABC", "DEF, GHI"})
+	if actual := strings.Join(ProjectKeys(), " "); actual != "ABC DEF GHI" {
+		t.Errorf("got: '%s', want: '%s'", actual, "ABC DEF GHI")
+	}
+}
+
+func TestValidateProjects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/project" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "secret" {
+			t.Errorf("Basic auth is invalid, got: %s %s", user, password)
+		}
+		fmt.Fprint(w, `[{"id":"1","key":"ABC"},{"id":"2","key":"DEF"}]`)
+	}))
+	defer server.Close()
+	viper.Set(GoopscJiraServerUrl, server.URL)
+	viper.Set(GoopscJiraUser, "user")
+	viper.Set(GoopscJiraPassword, "secret")
+	defer viper.Set(GoopscJiraServerUrl, "")
+
+	keys, err := validateProjects([]string{"ABC-1", "UTF-8", "DEF-2"})
+	if actual := strings.Join(keys, " "); err != nil || actual != "ABC-1 DEF-2" {
+		t.Errorf("got: '%s', err: %v, want: '%s'", actual, err, "ABC-1 DEF-2")
+	}
+}
+
+func TestGetIssuesReleaseScope(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	commits := "1111111aaaa\x1fABC-1 login\n\nRefs: ABC-2\n\x1e\n" +
+		"2222222bbbb\x1fMerge branch 'feature/ABC-3' into master\n\nUTF-8 support\n\x1e\n"
+	tables := []struct {
+		strategy string
+		expected string
+	}{
+		{GerritStrategy, "ABC-1 ABC-2 ABC-3 ABC-4"},
+		{GithubStrategy, "ABC-1 ABC-2 ABC-3"},
+	}
+
+	viper.Set(GoopscJira, "true")
+	viper.Set(GoopscJiraIssueScope, ReleaseScope)
+	viper.Set(GoopscJiraProjectKey, "ABC")
+	defer viper.Set(GoopscJiraIssueScope, ChangeScope)
+	defer viper.Set(GoopscJiraProjectKey, "")
+	defer viper.Set(GoopscJiraStrategy, GerritStrategy)
+	for _, table := range tables {
+		viper.Set(GoopscJiraStrategy, table.strategy)
+		mockIService := mock_execService.NewMockIService(ctrl)
+		mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return("", nil).AnyTimes()
+		mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly").Return("1.2.0", nil).AnyTimes()
+		mockIService.EXPECT().Exec("git --no-pager log --format=%H%x1f%B%x1e 1.2.0..HEAD").Return(commits, nil)
+		mockIService.EXPECT().Exec("git --no-pager log -1 --pretty=%B").Return("ABC-4 fix", nil).AnyTimes()
+		gitService.Initialize(mockIService)
+		j := New()
+		actual := strings.Join(j.GetIssues(), " ")
+		if actual != table.expected {
+			t.Errorf("strategy: %s, got: '%s', want: '%s'", table.strategy, actual, table.expected)
+		}
+	}
+}
+
+func TestGetIssuesReleaseScopeApiError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/app/pulls/7" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message":"Not Found"}`)
+	}))
+	defer server.Close()
+	viper.Set("GOOPSC_GITHUB_API_URL", server.URL)
+	viper.Set("GOOPSC_GITHUB_TOKEN", "secret")
+	viper.Set("GOOPSC_GITHUB_REPOSITORY", "owner/app")
+	viper.Set("GITHUB_REF", "refs/pull/7/merge")
+	viper.Set(GoopscJira, "true")
+	viper.Set(GoopscJiraStrategy, GithubStrategy)
+	viper.Set(GoopscJiraIssueScope, ReleaseScope)
+	viper.Set(GoopscJiraProjectKey, "ABC")
+	defer viper.Set("GOOPSC_GITHUB_REPOSITORY", "")
+	defer viper.Set("GITHUB_REF", "")
+	defer viper.Set(GoopscJiraStrategy, GerritStrategy)
+	defer viper.Set(GoopscJiraIssueScope, ChangeScope)
+	defer viper.Set(GoopscJiraProjectKey, "")
+	githubApi.Initialize()
+
+	mockIService := mock_execService.NewMockIService(ctrl)
+	mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return("", nil).AnyTimes()
+	mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly").Return("1.2.0", nil).AnyTimes()
+	mockIService.EXPECT().Exec("git --no-pager log --format=%H%x1f%B%x1e 1.2.0..HEAD").Return("1111111aaaa\x1fABC-1 login\n\nRefs: ABC-2\n\x1e\n", nil)
+	gitService.Initialize(mockIService)
+
+	j := New()
+	actual := strings.Join(j.GetIssues(), " ")
+	if actual != "ABC-1 ABC-2" {
+		t.Errorf("got: '%s', want: '%s'", actual, "ABC-1 ABC-2")
+	}
+}
+
+func TestSetJiraVersion(t *testing.T) {
+	requests := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, fmt.Sprintf("%s %s %s", r.Method, r.URL.Path, body))
+		switch fmt.Sprintf("%s %s", r.Method, r.URL.Path) {
+		case "GET /rest/api/2/project":
+			fmt.Fprint(w, `[{"id":"1","key":"ABC"}]`)
+		case "GET /rest/api/2/issue/ABC-1", "GET /rest/api/2/issue/ABC-2":
+			fmt.Fprint(w, `{"fields":{"project":{"key":"ABC"}}}`)
+		case "GET /rest/api/2/project/ABC/version":
+			if len(requests) > 3 {
+				fmt.Fprint(w, `{"values":[{"id":"1","name":"1.0.0"}],"isLast":true}`)
+				return
+			}
+			fmt.Fprint(w, `{"values":[],"isLast":true}`)
+		case "POST /rest/api/2/version":
+			fmt.Fprint(w, `{"id":"1","name":"1.0.0"}`)
+		case "POST /rest/api/2/issue":
+			fmt.Fprint(w, `{"key":"ABC-10"}`)
+		case "PUT /rest/api/2/issue/ABC-1", "PUT /rest/api/2/issue/ABC-2":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+	}))
+	defer server.Close()
+	viper.Set(GoopscJira, "true")
+	viper.Set(GoopscJiraServerUrl, server.URL)
+	viper.Set(GoopscJiraUser, "user")
+	viper.Set(GoopscJiraPassword, "secret")
+	viper.Set(GoopscJiraVersionAssign, "true")
+	viper.Set(GoopscJiraVersionCreate, "true")
+	viper.Set(GoopscJiraCreateDeploymentIssue, "true")
+	defer viper.Set(GoopscJiraServerUrl, "")
+
+	j := New()
+	j.SetJiraVersion("1.0.0", []string{"ABC-1", "UTF-8", "ABC-2"}, "", "", "")
+	expected := []string{
+		"GET /rest/api/2/project ",
+		"GET /rest/api/2/issue/ABC-1 ",
+		"GET /rest/api/2/project/ABC/version ",
+		`POST /rest/api/2/version {"name":"1.0.0","project":"ABC"}`,
+		`POST /rest/api/2/issue {"fields":{"fixVersions":[{"name":"1.0.0"}],"issuetype":{"name":"Task"},"project":{"key":"ABC"},"summary":"Deployment of version 1.0.0"}}`,
+		`PUT /rest/api/2/issue/ABC-1 {"update":{"fixVersions":[{"add":{"name":"1.0.0"}}]}}`,
+		"GET /rest/api/2/issue/ABC-2 ",
+		`PUT /rest/api/2/issue/ABC-2 {"update":{"fixVersions":[{"add":{"name":"1.0.0"}}]}}`,
+	}
+	if actual := strings.Join(requests, "\n"); actual != strings.Join(expected, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", actual, strings.Join(expected, "\n"))
+	}
+}
+
+func TestReleaseVersion(t *testing.T) {
+	tables := []struct {
+		options  ReleaseOptions
+		error    bool
+		expected []string
+	}{
+		{ReleaseOptions{Unresolved: UnresolvedFail}, true, []string{
+			"GET /rest/api/2/project/ABC/version ",
+			"GET /rest/api/2/search ",
+		}},
+		{ReleaseOptions{Unresolved: UnresolvedWarn}, false, []string{
+			"GET /rest/api/2/project/ABC/version ",
+			"GET /rest/api/2/search ",
+			`PUT /rest/api/2/version/1 {"released":true,"releaseDate":"2019-04-12"}`,
+		}},
+		{ReleaseOptions{MoveUnresolvedTo: "1.1.0"}, false, []string{
+			"GET /rest/api/2/project/ABC/version ",
+			"GET /rest/api/2/search ",
+			"GET /rest/api/2/project/ABC/version ",
+			`POST /rest/api/2/version {"name":"1.1.0","project":"ABC"}`,
+			`PUT /rest/api/2/issue/ABC-2 {"update":{"fixVersions":[{"remove":{"name":"1.0.0"}},{"add":{"name":"1.1.0"}}]}}`,
+			`PUT /rest/api/2/version/1 {"released":true,"releaseDate":"2019-04-12"}`,
+		}},
+		{ReleaseOptions{Unresolved: "skip"}, true, []string{}},
+	}
+
+	viper.Set(GoopscJira, "true")
+	viper.Set(GoopscJiraUser, "user")
+	viper.Set(GoopscJiraPassword, "secret")
+	viper.Set(GoopscJiraProjectKey, "ABC")
+	viper.Set(GoopscJiraVersionCreate, "true")
+	defer viper.Set(GoopscJiraServerUrl, "")
+	defer viper.Set(GoopscJiraProjectKey, "")
+	for _, table := range tables {
+		requests := make([]string, 0)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			requests = append(requests, fmt.Sprintf("%s %s %s", r.Method, r.URL.Path, body))
+			switch fmt.Sprintf("%s %s", r.Method, r.URL.Path) {
+			case "GET /rest/api/2/project/ABC/version":
+				fmt.Fprint(w, `{"values":[{"id":"1","name":"1.0.0"}],"isLast":true}`)
+			case "GET /rest/api/2/search":
+				if jql := r.URL.Query().Get("jql"); jql != `project = "ABC" AND fixVersion = "1.0.0"` {
+					t.Errorf("unexpected jql: %s", jql)
+				}
+				fmt.Fprint(w, `{"issues":[{"key":"ABC-1","fields":{"status":{"statusCategory":{"key":"done"}}}},{"key":"ABC-2","fields":{"status":{"statusCategory":{"key":"indeterminate"}}}}],"total":2}`)
+			case "POST /rest/api/2/version":
+				fmt.Fprint(w, `{"id":"2","name":"1.1.0"}`)
+			case "PUT /rest/api/2/issue/ABC-2":
+				w.WriteHeader(http.StatusNoContent)
+			case "PUT /rest/api/2/version/1":
+				fmt.Fprint(w, `{"id":"1","name":"1.0.0","released":true}`)
+			default:
+				t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			}
+		}))
+		viper.Set(GoopscJiraServerUrl, server.URL)
+		table.options.Date = time.Date(2019, 4, 12, 0, 0, 0, 0, time.UTC)
+		j := New()
+		err := j.ReleaseVersion("1.0.0", table.options)
+		server.Close()
+		if (err != nil) != table.error {
+			t.Errorf("options: %+v, unexpected error: %v", table.options, err)
+		}
+		if actual := strings.Join(requests, "\n"); actual != strings.Join(table.expected, "\n") {
+			t.Errorf("options: %+v, got:\n%s\nwant:\n%s", table.options, actual, strings.Join(table.expected, "\n"))
+		}
+	}
+}
+
+const testWorkflow = `workflow:
+  code review:
+    default: ready to test
+  in test:
+    done: done
+    default: bug found
+  to do:
+    rejected: reject
+    default: start progress
+  in progress:
+    default: code review
+  done:
+    default: reopen
+  rejected:
+    default: reopen
+`
+
+func TestParseWorkflow(t *testing.T) {
+	w, err := ParseWorkflow([]byte(testWorkflow))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.defaults["in test"] != "bug found" || w.transitions["in test"]["done"] != "done" || w.transitions["to do"]["rejected"] != "reject" {
+		t.Errorf("unexpected workflow: %+v", w)
+	}
+	w.resolved["to do"] = "in progress"
+	w.resolved["in progress"] = "code review"
+	w.resolved["code review"] = "in test"
+	hops, ok := w.path("to do", "done")
+	expected := []hop{{"start progress", "in progress"}, {"code review", "code review"}, {"ready to test", "in test"}, {"done", "done"}}
+	if !ok || fmt.Sprint(hops) != fmt.Sprint(expected) {
+		t.Errorf("got: %v, want: %v", hops, expected)
+	}
+	if _, ok := w.path("done", "to do"); ok {
+		t.Errorf("expected path not found, target of default transition is not resolved")
+	}
+	if _, err := ParseWorkflow([]byte("states: []")); err == nil {
+		t.Errorf("expected error for invalid workflow")
+	}
+	if _, err := ParseWorkflow([]byte("workflow:\n  to do: [start progress]\n")); err == nil {
+		t.Errorf("expected error for status not mapping target statuses")
+	}
+}
+
+func TestParseWorkflowDottedStatus(t *testing.T) {
+	w, err := ParseWorkflow([]byte(`workflow:
+  In Review v1.2:
+    Ready.QA: Approve
+    default: Reject
+  Ready.QA:
+    default: Deploy
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.transitions["in review v1.2"]["ready.qa"] != "Approve" || w.defaults["in review v1.2"] != "Reject" || w.defaults["ready.qa"] != "Deploy" {
+		t.Errorf("unexpected workflow: %+v", w)
+	}
+	if hops, ok := w.path("in review v1.2", "ready.qa"); !ok || len(hops) != 1 || hops[0].transition != "Approve" {
+		t.Errorf("got: %v, want path through Approve transition", hops)
+	}
+}
+
+func TestJiraTransition(t *testing.T) {
+	transitions := map[string]map[string]string{
+		"To Do":       {"Start Progress": "In Progress", "Reject": "Rejected"},
+		"In Progress": {"Code Review": "Code Review"},
+		"Code Review": {"Ready to test": "In Test"},
+		"In Test":     {"Done": "Done", "Bug found": "In Progress"},
+		"Done":        {"Reopen": "To Do"},
+		"Rejected":    {"Reopen": "To Do"},
+	}
+	statuses := map[string]string{"ABC-1": "To Do", "ABC-2": "Done", "ABC-3": "In Test"}
+	executed := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rest/api/2/project" {
+			fmt.Fprint(w, `[{"id":"1","key":"ABC"}]`)
+			return
+		}
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/rest/api/2/issue/"), "/")
+		key := parts[0]
+		switch {
+		case r.Method == http.MethodGet && len(parts) == 1:
+			fmt.Fprintf(w, `{"key":"%s","fields":{"status":{"name":"%s"}}}`, key, statuses[key])
+		case r.Method == http.MethodGet:
+			available := make([]jiraApi.Transition, 0)
+			for name, to := range transitions[statuses[key]] {
+				available = append(available, jiraApi.Transition{Id: name, Name: name, To: jiraApi.Status{Name: to}})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"transitions": available})
+		default:
+			var body struct {
+				Transition jiraApi.Transition `json:"transition"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			executed = append(executed, fmt.Sprintf("%s: %s", key, body.Transition.Id))
+			statuses[key] = transitions[statuses[key]][body.Transition.Id]
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+	viper.Set(GoopscJira, "true")
+	viper.Set(GoopscJiraIssueTransition, "true")
+	viper.Set(GoopscJiraServerUrl, server.URL)
+	viper.Set(GoopscJiraUser, "user")
+	viper.Set(GoopscJiraPassword, "secret")
+	viper.Set(GoopscJiraWorkflowContent, testWorkflow)
+	defer viper.Set(GoopscJiraServerUrl, "")
+	defer viper.Set(GoopscJiraWorkflowContent, "")
+
+	j := New()
+	if err := j.JiraTransition("ABC-1 ABC-2 UTF-8 ABC-3", "done"); err != nil {
+		t.Error(err)
+	}
+	expected := []string{
+		"ABC-1: Start Progress", "ABC-1: Code Review", "ABC-1: Ready to test", "ABC-1: Done",
+		"ABC-3: Done",
+	}
+	if strings.Join(executed, ", ") != strings.Join(expected, ", ") {
+		t.Errorf("got: %v, want: %v", executed, expected)
+	}
+
+	executed = executed[:0]
+	if err := j.JiraTransition("ABC-2", "Rejected"); err != nil {
+		t.Error(err)
+	}
+	if strings.Join(executed, ", ") != "ABC-2: Reopen, ABC-2: Reject" {
+		t.Errorf("got: %v", executed)
+	}
+
+	err := j.JiraTransition("ABC-1 ABC-3", "Closed")
+	if err == nil || err.Error() != "issues not transitioned to state: Closed: ABC-1, ABC-3" {
+		t.Errorf("expected error listing unreachable issues, got: %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+package jira
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/sotomskir/goops/jiraApi"
+	"github.com/spf13/viper"
+	"gopkg.in/resty.v1"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTransition   = "default"
+	defaultWorkflowFile = "workflow.yaml"
+)
+
+// Workflow is graph of Jira workflow defined in workflow.yaml, statuses are lower case.
+type Workflow struct {
+	// transitions maps source status to target status to transition name
+	transitions map[string]map[string]string
+	// defaults maps source status to default transition name
+	defaults map[string]string
+	// resolved maps source status to target status of default transition, learned from Jira while transitioning
+	resolved map[string]string
+}
+
+type hop struct {
+	transition string
+	status     string
+}
+
+// LoadWorkflow reads workflow from GOOPSC_JIRA_WORKFLOW_CONTENT, or from GOOPSC_JIRA_WORKFLOW local file or http url.
+// Nil workflow is returned when workflow is not defined and default workflow file does not exist.
+func LoadWorkflow() (*Workflow, error) {
+	if content := viper.GetString(GoopscJiraWorkflowContent); content != "" {
+		return ParseWorkflow([]byte(content))
+	}
+	location := viper.GetString(GoopscJiraWorkflow)
+	configured := location != ""
+	if !configured {
+		location = defaultWorkflowFile
+	}
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		res, err := resty.New().SetTimeout(1 * time.Minute).R().Get(location)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode() >= 400 {
+			return nil, fmt.Errorf("GET: %s\nStatus code: %d\nResponse: %s", location, res.StatusCode(), string(res.Body()))
+		}
+		return ParseWorkflow(res.Body())
+	}
+	content, err := ioutil.ReadFile(location)
+	if os.IsNotExist(err) && !configured {
+		logrus.Debugf("Workflow file: %s does not exist, issues are transitioned directly\n", location)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseWorkflow(content)
+}
+
+// ParseWorkflow parses workflow yaml mapping source status to target status to transition name,
+// "default" target is transition taken from source status when target status is not listed.
+func ParseWorkflow(content []byte) (*Workflow, error) {
+	// statuses may contain dots, so yaml is not read with viper which splits keys on dots
+	parsed := struct {
+		Workflow map[string]map[string]string `yaml:"workflow"`
+	}{}
+	if err := yaml.Unmarshal(content, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid workflow, status must map target statuses to transitions: %s", err)
+	}
+	if parsed.Workflow == nil {
+		return nil, fmt.Errorf("invalid workflow, 'workflow' key not found")
+	}
+	w := &Workflow{
+		transitions: make(map[string]map[string]string),
+		defaults:    make(map[string]string),
+		resolved:    make(map[string]string),
+	}
+	for source, targets := range parsed.Workflow {
+		source = strings.ToLower(source)
+		w.transitions[source] = make(map[string]string)
+		for target, transition := range targets {
+			if strings.ToLower(target) == defaultTransition {
+				w.defaults[source] = transition
+				continue
+			}
+			w.transitions[source][strings.ToLower(target)] = transition
+		}
+	}
+	return w, nil
+}
+
+// path returns shortest sequence of transitions from status to target through statuses known from workflow,
+// false is returned when target is not reachable through known statuses.
+func (w *Workflow) path(from string, to string) ([]hop, bool) {
+	previous := map[string]hop{from: {}}
+	sources := map[string]string{}
+	queue := []string{from}
+	for len(queue) > 0 {
+		status := queue[0]
+		queue = queue[1:]
+		if status == to {
+			hops := make([]hop, 0)
+			for status != from {
+				hops = append([]hop{previous[status]}, hops...)
+				status = sources[status]
+			}
+			return hops, true
+		}
+		for _, next := range w.next(status) {
+			if _, visited := previous[next.status]; !visited {
+				previous[next.status] = next
+				sources[next.status] = status
+				queue = append(queue, next.status)
+			}
+		}
+	}
+	return nil, false
+}
+
+// next returns transitions from status, explicit transitions are sorted by target status so path is deterministic
+func (w *Workflow) next(status string) []hop {
+	hops := make([]hop, 0)
+	for _, target := range sortedKeys(w.transitions[status]) {
+		hops = append(hops, hop{transition: w.transitions[status][target], status: target})
+	}
+	if target, ok := w.resolved[status]; ok {
+		hops = append(hops, hop{transition: w.defaults[status], status: target})
+	}
+	return hops
+}
+
+// learn resolves target status of default transition from transitions available in status
+func (w *Workflow) learn(status string, available []jiraApi.Transition) {
+	name, ok := w.defaults[status]
+	if !ok {
+		return
+	}
+	for _, transition := range available {
+		if strings.EqualFold(transition.Name, name) {
+			w.resolved[status] = strings.ToLower(transition.To.Name)
+		}
+	}
+}
+
+// TransitionIssue moves issue to target status executing each transition of shortest path found in workflow.
+// Default transition is followed when path through known statuses does not exist.
+func (w *Workflow) TransitionIssue(issueKey string, target string) error {
+	issue, err := jiraApi.GetIssue(issueKey)
+	if err != nil {
+		return err
+	}
+	current := strings.ToLower(issue.Fields.Status.Name)
+	target = strings.ToLower(target)
+	visited := make(map[string]bool)
+	for current != target {
+		if visited[current] {
+			return fmt.Errorf("issue: %s can not reach status: %s, workflow loops back to status: %s", issueKey, target, current)
+		}
+		visited[current] = true
+		available, err := jiraApi.GetTransitions(issueKey)
+		if err != nil {
+			return err
+		}
+		w.learn(current, available)
+		name := w.defaults[current]
+		if hops, ok := w.path(current, target); ok {
+			name = hops[0].transition
+		}
+		if name == "" {
+			return fmt.Errorf("issue: %s can not reach status: %s, no transition from status: %s in workflow", issueKey, target, current)
+		}
+		transition, ok := findTransition(available, name)
+		if !ok {
+			return fmt.Errorf("issue: %s can not reach status: %s, transition: %s is not available in status: %s", issueKey, target, name, current)
+		}
+		logrus.Infof("Transition issue: %s from status: %s using transition: %s\n", issueKey, current, transition.Name)
+		if err := jiraApi.DoTransition(issueKey, transition.Id); err != nil {
+			return err
+		}
+		current = strings.ToLower(transition.To.Name)
+	}
+	return nil
+}
+
+func findTransition(available []jiraApi.Transition, name string) (jiraApi.Transition, bool) {
+	for _, transition := range available {
+		if strings.EqualFold(transition.Name, name) {
+			return transition, true
+		}
+	}
+	return jiraApi.Transition{}, false
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
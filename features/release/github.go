@@ -0,0 +1,29 @@
+package release
+
+import (
+	"errors"
+	"github.com/sotomskir/goops/features/semver"
+	"github.com/sotomskir/goops/githubApi"
+	"strings"
+)
+
+// PublishGithubRelease creates or updates GitHub release for version tagged on HEAD.
+// Release notes are generated from commits since previous tag, prerelease versions are marked as prerelease.
+func PublishGithubRelease(s semver.Semver, draft bool) (githubApi.Release, error) {
+	repo := githubApi.GetRepository()
+	if repo == "" {
+		return githubApi.Release{}, errors.New("GitHub repository is not set, use GOOPSC_GITHUB_REPOSITORY variable")
+	}
+	version, tag, notes, err := headReleaseNotes(s)
+	if err != nil {
+		return githubApi.Release{}, err
+	}
+	return githubApi.SaveRelease(repo, githubApi.Release{
+		TagName: tag,
+		Name:    tag,
+		Body:    notes,
+		Draft:   draft,
+		// Both semver and calver formats separate prerelease identifiers with "-"
+		Prerelease: strings.Contains(version, "-"),
+	}), nil
+}
@@ -0,0 +1,46 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sotomskir/goops/features/semver"
+	"github.com/sotomskir/goops/gitlabApi"
+	"github.com/spf13/viper"
+	"strings"
+)
+
+// PublishGitlabRelease creates or updates GitLab release for version tagged on HEAD.
+// Release notes are generated from commits since previous tag. Links are in name=url format.
+func PublishGitlabRelease(s semver.Semver, links []string, milestones []string) (gitlabApi.Release, error) {
+	projectId := viper.GetString("CI_PROJECT_ID")
+	if projectId == "" {
+		return gitlabApi.Release{}, errors.New("CI_PROJECT_ID is not set")
+	}
+	assetLinks, err := parseLinks(links)
+	if err != nil {
+		return gitlabApi.Release{}, err
+	}
+	_, tag, notes, err := headReleaseNotes(s)
+	if err != nil {
+		return gitlabApi.Release{}, err
+	}
+	return gitlabApi.SaveRelease(projectId, gitlabApi.ReleaseOptions{
+		Name:        tag,
+		TagName:     tag,
+		Description: notes,
+		Milestones:  milestones,
+		Assets:      gitlabApi.ReleaseAssets{Links: assetLinks},
+	}), nil
+}
+
+func parseLinks(links []string) ([]gitlabApi.ReleaseLink, error) {
+	result := make([]gitlabApi.ReleaseLink, 0, len(links))
+	for _, link := range links {
+		parts := strings.SplitN(link, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid link: '%s', expected format: name=url", link)
+		}
+		result = append(result, gitlabApi.ReleaseLink{Name: parts[0], Url: parts[1]})
+	}
+	return result, nil
+}
@@ -0,0 +1,71 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/sotomskir/goops/features/changelog"
+	"github.com/sotomskir/goops/features/semver"
+	"github.com/sotomskir/goops/gitService"
+	"github.com/sotomskir/goops/utils"
+	"github.com/spf13/viper"
+)
+
+const (
+	// Configuration variables
+	GoopscReleaseRemote     = "GOOPSC_RELEASE_REMOTE"
+	GoopscReleasePush       = "GOOPSC_RELEASE_PUSH"
+	GoopscReleaseSign       = "GOOPSC_RELEASE_SIGN"
+	GoopscReleaseSigningKey = "GOOPSC_RELEASE_SIGNING_KEY"
+)
+
+func setDefaults() {
+	viper.SetDefault(GoopscReleaseRemote, "origin")
+	viper.SetDefault(GoopscReleasePush, "true")
+	viper.SetDefault(GoopscReleaseSign, "false")
+	viper.SetDefault(GoopscReleaseSigningKey, "")
+}
+
+// Release computes next release version, creates annotated tag on HEAD and pushes it to remote.
+// Bump level is one of major, minor, patch, when empty it is computed by semver strategy.
+// Returns created tag name.
+func Release(s semver.Semver, level string) (string, error) {
+	setDefaults()
+	clean, err := gitService.IsWorkingTreeClean()
+	if err != nil {
+		return "", err
+	}
+	if !clean {
+		return "", errors.New("working tree is dirty, commit or stash changes before release")
+	}
+	version, tag, err := s.GetReleaseVersion(level)
+	if err != nil {
+		return "", err
+	}
+	logrus.Infof("Creating tag: %s\n", tag)
+	err = gitService.CreateTag(tag, fmt.Sprintf("Release %s", version), utils.IsEnabled(GoopscReleaseSign), viper.GetString(GoopscReleaseSigningKey))
+	if err != nil {
+		return "", err
+	}
+	if utils.IsEnabled(GoopscReleasePush) {
+		remote := viper.GetString(GoopscReleaseRemote)
+		logrus.Infof("Pushing tag: %s to remote: %s\n", tag, remote)
+		if err := gitService.PushTag(remote, tag); err != nil {
+			return "", err
+		}
+	}
+	return tag, nil
+}
+
+// headReleaseNotes returns version and tag on HEAD with changelog of commits since previous tag as release notes.
+func headReleaseNotes(s semver.Semver) (string, string, string, error) {
+	version, tag, tagged := s.GetHeadVersion()
+	if !tagged {
+		return "", "", "", errors.New("HEAD is not tagged, run goops release first")
+	}
+	notes, err := changelog.Generate(s, version)
+	if err != nil {
+		return "", "", "", err
+	}
+	return version, tag, notes.Markdown(), nil
+}
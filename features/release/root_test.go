@@ -0,0 +1,187 @@
+package release
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/sotomskir/goops/features/semver"
+	"github.com/sotomskir/goops/gitService"
+	"github.com/sotomskir/goops/githubApi"
+	"github.com/sotomskir/goops/gitlabApi"
+	"github.com/sotomskir/goops/mockExecService"
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRelease(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tables := []struct {
+		level    string
+		push     string
+		expected string
+	}{
+		{"", "true", "v1.3.0"},
+		{"major", "true", "v2.0.0"},
+		{"patch", "false", "v1.2.1"},
+	}
+
+	viper.Set(semver.GoopscSemverStrategy, semver.GithubFlowStrategy)
+	viper.Set(semver.GoopscSemverTagPrefix, "v")
+	viper.Set(GoopscReleaseRemote, "upstream")
+	defer viper.Set(semver.GoopscSemverTagPrefix, "")
+	s := semver.New()
+	for _, table := range tables {
+		viper.Set(GoopscReleasePush, table.push)
+		mockIService := mock_execService.NewMockIService(ctrl)
+		mockIService.EXPECT().Exec("git status --porcelain").Return("", nil)
+		mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return("", nil).AnyTimes()
+		mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly --match v*").Return("v1.2.0", nil).AnyTimes()
+		mockIService.EXPECT().ExecArgs("git", "tag", "--annotate", table.expected, "--message", "Release "+table.expected[1:]).Return("", nil)
+		if table.push == "true" {
+			mockIService.EXPECT().ExecArgs("git", "push", "upstream", "refs/tags/"+table.expected).Return("", nil)
+		}
+		gitService.Initialize(mockIService)
+		actual, err := Release(s, table.level)
+		if err != nil || actual != table.expected {
+			t.Errorf("level: '%s', got: '%s', want: '%s', err: %v", table.level, actual, table.expected, err)
+		}
+	}
+}
+
+func TestReleaseRefused(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	viper.Set(semver.GoopscSemverStrategy, semver.GithubFlowStrategy)
+	s := semver.New()
+
+	// dirty working tree
+	mockIService := mock_execService.NewMockIService(ctrl)
+	mockIService.EXPECT().Exec("git status --porcelain").Return(" M README.md", nil)
+	gitService.Initialize(mockIService)
+	if _, err := Release(s, ""); err == nil {
+		t.Errorf("expected error for dirty working tree")
+	}
+
+	// HEAD already tagged
+	mockIService = mock_execService.NewMockIService(ctrl)
+	mockIService.EXPECT().Exec("git status --porcelain").Return("", nil)
+	mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return("1.2.0", nil)
+	gitService.Initialize(mockIService)
+	if _, err := Release(s, ""); err == nil {
+		t.Errorf("expected error for tagged HEAD")
+	}
+
+	// invalid bump level
+	mockIService = mock_execService.NewMockIService(ctrl)
+	mockIService.EXPECT().Exec("git status --porcelain").Return("", nil)
+	mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return("", nil)
+	gitService.Initialize(mockIService)
+	if _, err := Release(s, "huge"); err == nil {
+		t.Errorf("expected error for invalid bump level")
+	}
+
+	// tag creation failure
+	mockIService = mock_execService.NewMockIService(ctrl)
+	mockIService.EXPECT().Exec("git status --porcelain").Return("", nil)
+	mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return("", nil)
+	mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly").Return("1.2.0", nil)
+	mockIService.EXPECT().ExecArgs("git", "tag", "--annotate", "1.2.1", "--message", "Release 1.2.1").Return("", errors.New("exit status 128"))
+	gitService.Initialize(mockIService)
+	if _, err := Release(s, "patch"); err == nil {
+		t.Errorf("expected error when tag cannot be created")
+	}
+}
+
+func TestPublishGitlabRelease(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var payload gitlabApi.ReleaseOptions
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+		case http.MethodPost:
+			body, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(body, &payload)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"name":"1.3.0","tag_name":"1.3.0"}`))
+		}
+	}))
+	defer server.Close()
+	viper.Set("ci_api_v4_url", server.URL)
+	viper.Set("ci_gitlab_token", "secret")
+	viper.Set("CI_PROJECT_ID", "42")
+	defer viper.Set("CI_PROJECT_ID", "")
+	gitlabApi.Initialize()
+
+	viper.Set(semver.GoopscSemverStrategy, semver.GithubFlowStrategy)
+	mockIService := mock_execService.NewMockIService(ctrl)
+	mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return("1.3.0", nil).AnyTimes()
+	mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly --exclude 1.3.0").Return("1.2.0", nil)
+	mockIService.EXPECT().Exec("git --no-pager log --format=%H%x1f%B%x1e 1.2.0..HEAD").Return("1111111aaaa\x1ffeat: add login\x1e", nil)
+	gitService.Initialize(mockIService)
+
+	release, err := PublishGitlabRelease(semver.New(), []string{"Docker image=registry.example.com/app:1.3.0"}, []string{"1.3"})
+	if err != nil || release.TagName != "1.3.0" {
+		t.Fatalf("got: %#v, err: %v", release, err)
+	}
+	if !strings.Contains(payload.Description, "- add login (1111111)") || payload.Assets.Links[0].Url != "registry.example.com/app:1.3.0" || payload.Milestones[0] != "1.3" {
+		t.Errorf("Request is invalid, got: %#v", payload)
+	}
+
+	if _, err := PublishGitlabRelease(semver.New(), []string{"Docker image"}, nil); err == nil {
+		t.Errorf("expected error for invalid link")
+	}
+}
+
+func TestPublishGithubRelease(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var payload githubApi.Release
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			if r.URL.Path == "/repos/owner/app/releases" {
+				fmt.Fprint(w, `[]`)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		case http.MethodPost:
+			body, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(body, &payload)
+			w.WriteHeader(http.StatusCreated)
+			w.Write(body)
+		}
+	}))
+	defer server.Close()
+	viper.Set("GOOPSC_GITHUB_API_URL", server.URL)
+	viper.Set("GOOPSC_GITHUB_TOKEN", "secret")
+	viper.Set("GOOPSC_GITHUB_REPOSITORY", "owner/app")
+	defer viper.Set("GOOPSC_GITHUB_REPOSITORY", "")
+	githubApi.Initialize()
+
+	viper.Set(semver.GoopscSemverStrategy, semver.GithubFlowStrategy)
+	mockIService := mock_execService.NewMockIService(ctrl)
+	mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return("1.3.0-rc.1", nil).AnyTimes()
+	mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly --exclude 1.3.0-rc.1").Return("1.2.0", nil)
+	mockIService.EXPECT().Exec("git --no-pager log --format=%H%x1f%B%x1e 1.2.0..HEAD").Return("1111111aaaa\x1ffix: login\x1e", nil)
+	gitService.Initialize(mockIService)
+
+	release, err := PublishGithubRelease(semver.New(), false)
+	if err != nil || release.TagName != "1.3.0-rc.1" {
+		t.Fatalf("got: %#v, err: %v", release, err)
+	}
+	if !payload.Prerelease || payload.Draft || !strings.Contains(payload.Body, "- login (1111111)") {
+		t.Errorf("Request is invalid, got: %#v", payload)
+	}
+}
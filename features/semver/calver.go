@@ -0,0 +1,124 @@
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// now is replaced in tests
+var now = time.Now
+
+// calverTokens maps calendar versioning tokens (https://calver.org) to regular expressions matching them.
+var calverTokens = map[string]string{
+	"YYYY": "(\\d{4})",
+	"YY":   "(\\d{1,3})",
+	"0Y":   "(\\d{2,3})",
+	"MM":   "(\\d{1,2})",
+	"0M":   "(\\d{2})",
+	"WW":   "(\\d{1,2})",
+	"0W":   "(\\d{2})",
+	"DD":   "(\\d{1,2})",
+	"0D":   "(\\d{2})",
+}
+
+const calverMicro = "MICRO"
+
+// calverFormat is calendar version format e.g. YYYY.0M.MICRO
+// First two segments are date tokens mapped to major and minor version, MICRO is mapped to patch version.
+type calverFormat struct {
+	layout string
+	tokens []string
+	regex  *regexp.Regexp
+}
+
+func newCalverFormat(layout string) (calverFormat, error) {
+	segments := strings.Split(layout, ".")
+	if len(segments) != 3 || segments[2] != calverMicro {
+		return calverFormat{}, fmt.Errorf("invalid calver format: '%s', expected format like YYYY.0M.MICRO", layout)
+	}
+	for _, token := range segments[:2] {
+		if _, ok := calverTokens[token]; !ok {
+			return calverFormat{}, fmt.Errorf("invalid calver format: '%s', unsupported token: %s", layout, token)
+		}
+	}
+	regex := regexp.MustCompile(fmt.Sprintf("^%s\\.%s\\.(0|[1-9]\\d*)(.*)$", calverTokens[segments[0]], calverTokens[segments[1]]))
+	return calverFormat{layout: layout, tokens: segments[:2], regex: regex}, nil
+}
+
+func (f calverFormat) parse(version string) (Version, error) {
+	match := f.regex.FindStringSubmatch(version)
+	if match == nil {
+		return Version{}, fmt.Errorf("version: '%s' does not match calver format: '%s'", version, f.layout)
+	}
+	// Reuse semver parser for prerelease and build metadata
+	v, err := Parse("0.0.0" + match[4])
+	if err != nil {
+		return Version{}, fmt.Errorf("invalid calver version: '%s': %s", version, err)
+	}
+	v.Major, _ = strconv.Atoi(match[1])
+	v.Minor, _ = strconv.Atoi(match[2])
+	v.Patch, _ = strconv.Atoi(match[3])
+	return v, nil
+}
+
+func (f calverFormat) format(version Version) string {
+	suffix := strings.TrimPrefix(version.String(), fmt.Sprintf("%d.%d.%d", version.Major, version.Minor, version.Patch))
+	return fmt.Sprintf("%s.%s.%d%s", formatCalverToken(f.tokens[0], version.Major), formatCalverToken(f.tokens[1], version.Minor), version.Patch, suffix)
+}
+
+// period returns major and minor version for given date.
+// When format contains week token ISO week-numbering year is used.
+func (f calverFormat) period(date time.Time) (int, int) {
+	year, week := date.Year(), 0
+	for _, token := range f.tokens {
+		if token == "WW" || token == "0W" {
+			year, week = date.ISOWeek()
+		}
+	}
+	values := make([]int, 0, 2)
+	for _, token := range f.tokens {
+		switch token {
+		case "YYYY":
+			values = append(values, year)
+		case "YY", "0Y":
+			values = append(values, year-2000)
+		case "MM", "0M":
+			values = append(values, int(date.Month()))
+		case "WW", "0W":
+			values = append(values, week)
+		case "DD", "0D":
+			values = append(values, date.Day())
+		}
+	}
+	return values[0], values[1]
+}
+
+func formatCalverToken(token string, value int) string {
+	if strings.HasPrefix(token, "0") {
+		return fmt.Sprintf("%02d", value)
+	}
+	return strconv.Itoa(value)
+}
+
+type calver struct {
+	format calverFormat
+}
+
+func (c calver) getSemanticVersion(tags tagScope) (Version, error) {
+	if headVersion, tagged := tags.headVersion(); tagged {
+		return headVersion, nil
+	}
+	date := now()
+	major, minor := c.format.period(date)
+	version := Version{Major: major, Minor: minor}
+	tags.trace.add("Current period for %s: %s", date.Format("2006-01-02"), tags.formatVersion(version))
+	previousVersion, previousTag := tags.previousVersion()
+	if previousTag != "" && previousVersion.Major == major && previousVersion.Minor == minor {
+		version.Patch = previousVersion.Patch + 1
+		tags.trace.add("Previous version is from current period, bumped micro version: %s", tags.formatVersion(version))
+	}
+	return snapshotVersion(version, tags)
+}
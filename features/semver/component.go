@@ -0,0 +1,88 @@
+package semver
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"regexp"
+	"strings"
+)
+
+// Component is independently versioned part of repository configured in .goops.yaml e.g.
+//
+//	goopsc_semver_components:
+//	- name: api
+//	  tag_prefix: api/
+//	  paths:
+//	  - services/api
+type Component struct {
+	Name      string   `mapstructure:"name"`
+	TagPrefix string   `mapstructure:"tag_prefix"`
+	Paths     []string `mapstructure:"paths"`
+}
+
+var nonAlphanumericRegex = regexp.MustCompile("[^A-Z0-9]+")
+
+// Components returns Semver for each configured component. Components use the same strategy as o.
+func (o *Semver) Components() ([]Semver, error) {
+	var components []Component
+	if err := viper.UnmarshalKey(GoopscSemverComponents, &components); err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", GoopscSemverComponents, err)
+	}
+	result := make([]Semver, 0, len(components))
+	for _, component := range components {
+		if component.Name == "" {
+			return nil, fmt.Errorf("invalid %s: component name is required", GoopscSemverComponents)
+		}
+		prefix := component.TagPrefix
+		if prefix == "" {
+			prefix = component.Name + "/"
+		}
+		result = append(result, Semver{
+			name:         component.Name,
+			strategyName: o.strategyName,
+			strategy:     o.strategy,
+			tags:         tagScope{prefix: prefix, paths: component.Paths, format: o.tags.format},
+		})
+	}
+	return result, nil
+}
+
+// Component returns Semver for component with given name.
+func (o *Semver) Component(name string) (Semver, error) {
+	components, err := o.Components()
+	if err != nil {
+		return Semver{}, err
+	}
+	for _, component := range components {
+		if component.name == name {
+			return component, nil
+		}
+	}
+	return Semver{}, fmt.Errorf("semver component not found: %s", name)
+}
+
+// GetComponentVersions computes and exports versions of all configured components.
+func (o *Semver) GetComponentVersions() (map[string]string, error) {
+	components, err := o.Components()
+	if err != nil {
+		return nil, err
+	}
+	versions := make(map[string]string)
+	for _, component := range components {
+		version, err := component.GetVersion()
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %s", component.name, err)
+		}
+		versions[component.name] = version
+	}
+	return versions, nil
+}
+
+// variable returns output variable name for component e.g. GOOPS_SEMVER_API_RELEASE for GOOPS_SEMVER_RELEASE
+func (o *Semver) variable(name string) string {
+	if o.name == "" {
+		return name
+	}
+	suffix := nonAlphanumericRegex.ReplaceAllString(strings.ToUpper(o.name), "_")
+	return strings.Replace(name, GoopsSemver, fmt.Sprintf("%s_%s", GoopsSemver, strings.Trim(suffix, "_")), 1)
+}
@@ -0,0 +1,108 @@
+package semver
+
+import (
+	"fmt"
+	"github.com/sotomskir/goops/gitService"
+	"regexp"
+	"strings"
+)
+
+type bump int
+
+const (
+	patchBump bump = iota
+	minorBump
+	majorBump
+)
+
+func parseBump(level string) (bump, error) {
+	switch strings.ToLower(level) {
+	case "major":
+		return majorBump, nil
+	case "minor":
+		return minorBump, nil
+	case "patch":
+		return patchBump, nil
+	}
+	return patchBump, fmt.Errorf("invalid bump level: '%s', expected one of: major, minor, patch", level)
+}
+
+func (b bump) String() string {
+	switch b {
+	case majorBump:
+		return "major"
+	case minorBump:
+		return "minor"
+	}
+	return "patch"
+}
+
+func (b bump) apply(version Version) Version {
+	switch b {
+	case majorBump:
+		return bumpMajorVersion(version)
+	case minorBump:
+		return bumpMinorVersion(version)
+	}
+	return bumpPatchVersion(version)
+}
+
+var conventionalHeaderRegex = regexp.MustCompile("^(\\w+)(\\([^)]*\\))?(!)?: ")
+var breakingChangeRegex = regexp.MustCompile("(?m)^BREAKING[ -]CHANGE: ")
+
+// ConventionalCommit is commit message parsed according to https://www.conventionalcommits.org
+type ConventionalCommit struct {
+	Type        string
+	Scope       string
+	Description string
+	Breaking    bool
+}
+
+// ParseConventionalCommit parses commit message. Second value is false when header does not follow
+// conventional commits format, description is then set to whole header.
+func ParseConventionalCommit(msg string) (ConventionalCommit, bool) {
+	header := strings.SplitN(msg, "\n", 2)[0]
+	c := ConventionalCommit{Description: header, Breaking: breakingChangeRegex.MatchString(msg)}
+	match := conventionalHeaderRegex.FindStringSubmatch(header)
+	if match == nil {
+		return c, false
+	}
+	c.Type = strings.ToLower(match[1])
+	c.Scope = strings.Trim(match[2], "()")
+	c.Breaking = c.Breaking || match[3] == "!"
+	c.Description = strings.TrimPrefix(header, match[0])
+	return c, true
+}
+
+type conventionalCommits struct{}
+
+func (conventionalCommits) getSemanticVersion(tags tagScope) (Version, error) {
+	if headVersion, tagged := tags.headVersion(); tagged {
+		return headVersion, nil
+	}
+	previousVersion, previousTag := tags.previousVersion()
+	messages, err := gitService.GetCommitMessages(previousTag, tags.paths...)
+	if err != nil {
+		return Version{}, err
+	}
+	b := getConventionalBump(messages)
+	version := b.apply(previousVersion)
+	tags.trace.add("Found %d commits since previous tag, bumped %s version: %s", len(messages), b, tags.formatVersion(version))
+	return snapshotVersion(version, tags)
+}
+
+// getConventionalBump returns the highest bump required by given commit messages.
+// Breaking changes bump major, feat bumps minor and any other commit bumps patch.
+func getConventionalBump(messages []string) bump {
+	result := patchBump
+	for _, msg := range messages {
+		c, _ := ParseConventionalCommit(msg)
+		if c.Breaking {
+			return majorBump
+		}
+		if c.Type == "feat" {
+			result = minorBump
+		}
+	}
+	return result
+}
@@ -0,0 +1,130 @@
+package semver
+
+import (
+	"fmt"
+	"github.com/sotomskir/goops/gitService"
+	"github.com/spf13/viper"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var branchVersionRegex = regexp.MustCompile("(\\d+\\.\\d+\\.\\d+)")
+var releaseBranchVersionRegex = regexp.MustCompile("^(\\d+)\\.(\\d+)\\b")
+
+// gitFlow implements versioning for git-flow branching model https://nvie.com/posts/a-successful-git-branching-model/
+type gitFlow struct {
+	master  string
+	develop string
+	feature string
+	release string
+	hotfix  string
+	support string
+}
+
+func newGitFlow() gitFlow {
+	return gitFlow{
+		master:  viper.GetString(GoopscSemverGitFlowMaster),
+		develop: viper.GetString(GoopscSemverGitFlowDevelop),
+		feature: viper.GetString(GoopscSemverGitFlowFeature),
+		release: viper.GetString(GoopscSemverGitFlowRelease),
+		hotfix:  viper.GetString(GoopscSemverGitFlowHotfix),
+		support: viper.GetString(GoopscSemverGitFlowSupport),
+	}
+}
+
+func (g gitFlow) getSemanticVersion(tags tagScope) (Version, error) {
+	if headVersion, tagged := tags.headVersion(); tagged {
+		return headVersion, nil
+	}
+	previousVersion, previousTag := tags.previousVersion()
+	branch := gitService.GetCurrentBranchName()
+	switch {
+	case branch == g.master:
+		tags.trace.add("Branch: %s is master branch, bumped patch version", branch)
+		return snapshotVersion(bumpPatchVersion(previousVersion), tags)
+	case branch == g.develop:
+		tags.trace.add("Branch: %s is develop branch", branch)
+		version, err := g.nextMinorVersion(previousVersion, tags)
+		if err != nil {
+			return Version{}, err
+		}
+		return prereleaseVersion(version, previousTag, tags, "alpha")
+	case strings.HasPrefix(branch, g.release):
+		tags.trace.add("Branch: %s is release branch", branch)
+		version := versionFromBranch(strings.TrimPrefix(branch, g.release), bumpMinorVersion(previousVersion))
+		return prereleaseVersion(version, previousTag, tags, "rc")
+	case strings.HasPrefix(branch, g.hotfix):
+		tags.trace.add("Branch: %s is hotfix branch", branch)
+		version := versionFromBranch(strings.TrimPrefix(branch, g.hotfix), bumpPatchVersion(previousVersion))
+		return prereleaseVersion(version, previousTag, tags, "rc")
+	case strings.HasPrefix(branch, g.support):
+		tags.trace.add("Branch: %s is support branch, bumped patch version", branch)
+		return snapshotVersion(bumpPatchVersion(previousVersion), tags)
+	case strings.HasPrefix(branch, g.feature):
+		tags.trace.add("Branch: %s is feature branch", branch)
+		name := sanitizeIdentifier(strings.TrimPrefix(branch, g.feature))
+		version, err := g.nextMinorVersion(previousVersion, tags)
+		if err != nil {
+			return Version{}, err
+		}
+		return prereleaseVersion(version, previousTag, tags, "alpha", name)
+	}
+	tags.trace.add("Branch: %s does not match any git-flow branch type", branch)
+	version, err := g.nextMinorVersion(previousVersion, tags)
+	if err != nil {
+		return Version{}, err
+	}
+	return snapshotVersion(version, tags)
+}
+
+// nextMinorVersion bumps minor version, skipping versions which already have release branch
+// e.g. release/1.3 or release/1.3.0 for 1.3.0.
+func (g gitFlow) nextMinorVersion(previousVersion Version, tags tagScope) (Version, error) {
+	version := bumpMinorVersion(previousVersion)
+	tags.trace.add("Bumped minor version: %s", version)
+	branches, err := gitService.GetRemoteBranches()
+	if err != nil {
+		return Version{}, err
+	}
+	releaseBranches := make(map[string]string)
+	for _, branch := range branches {
+		if !strings.HasPrefix(branch, g.release) {
+			continue
+		}
+		if match := releaseBranchVersionRegex.FindStringSubmatch(strings.TrimPrefix(branch, g.release)); match != nil {
+			releaseBranches[match[1]+"."+match[2]] = branch
+		}
+	}
+	for {
+		branch, exists := releaseBranches[fmt.Sprintf("%d.%d", version.Major, version.Minor)]
+		if !exists {
+			return version, nil
+		}
+		version = bumpMinorVersion(version)
+		tags.trace.add("Release branch: %s exists, bumped minor version: %s", branch, version)
+	}
+}
+
+// versionFromBranch returns version found in branch name, or defaultVersion when name does not contain version.
+func versionFromBranch(name string, defaultVersion Version) Version {
+	match := branchVersionRegex.FindStringSubmatch(name)
+	if match == nil {
+		return defaultVersion
+	}
+	version, err := Parse(match[1])
+	if err != nil {
+		return defaultVersion
+	}
+	return version
+}
+
+// prereleaseVersion returns version with given prerelease identifiers followed by number of commits since previous tag.
+func prereleaseVersion(version Version, previousTag string, tags tagScope, identifiers ...string) (Version, error) {
+	distance, err := gitService.GetCommitCount(previousTag, tags.paths...)
+	if err != nil {
+		return Version{}, err
+	}
+	tags.trace.add("Found %d commits since previous tag, prerelease version of: %s", distance, version)
+	return version.WithPrerelease(append(identifiers, strconv.Itoa(distance))...), nil
+}
@@ -1,29 +1,40 @@
 package semver
 
 import (
-	"fmt"
 	"github.com/sotomskir/goops/gitService"
 )
 
 type gitFlowBranch struct{}
 
-func (gitFlowBranch) getSemanticVersion() string {
-	previousMergedVersion, err := gitService.GetPreviouslyMergedVersion()
+func (gitFlowBranch) getSemanticVersion(tags tagScope) (Version, error) {
+	merged, err := gitService.GetPreviouslyMergedVersion()
 	if err != nil {
-		panic(err)
+		return Version{}, err
 	}
+	previousMergedVersion, err := Parse(merged)
+	if err != nil {
+		return Version{}, err
+	}
+	tags.trace.add("Previously merged version: %s", previousMergedVersion)
 	branch := gitService.GetCurrentBranchName()
 	if branch == "master" {
-		return previousMergedVersion
+		tags.trace.add("Branch: master, using previously merged version")
+		return previousMergedVersion, nil
 	}
-	var version string
+	var version Version
 	if isReleaseOrHotfixBranch(branch) {
-		version = getVersionFromBranchName(branch)
+		version, err = getVersionFromBranchName(branch)
+		if err != nil {
+			return Version{}, err
+		}
+		tags.trace.add("Branch: %s is release or hotfix branch, using version from branch name: %s", branch, version)
 	} else {
 		version = bumpMinorVersion(previousMergedVersion)
-		if gitService.BranchExists(version) {
+		tags.trace.add("Branch: %s, bumped minor version: %s", branch, version)
+		if gitService.BranchExists(version.String()) {
 			version = bumpMinorVersion(version)
+			tags.trace.add("Branch for version exists, bumped minor version: %s", version)
 		}
 	}
-	return fmt.Sprintf("%s-SNAPSHOT", version)
+	return snapshotVersion(version, tags)
 }
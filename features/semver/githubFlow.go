@@ -1,22 +1,13 @@
 package semver
 
-import (
-	"fmt"
-	"github.com/sotomskir/goops/gitService"
-)
-
 type githubFlow struct{}
 
-func (githubFlow) getSemanticVersion() string {
-	headTag := gitService.GetHeadTag()
-	if headTag != "" {
-		return headTag
-	}
-	previousTag := gitService.GetPreviousTag()
-	var version string
-	if previousTag == "" {
-		previousTag = "0.0.0"
+func (githubFlow) getSemanticVersion(tags tagScope) (Version, error) {
+	if headVersion, tagged := tags.headVersion(); tagged {
+		return headVersion, nil
 	}
-	version = bumpMinorVersion(previousTag)
-	return fmt.Sprintf("%s-SNAPSHOT", version)
+	previousVersion, _ := tags.previousVersion()
+	version := bumpMinorVersion(previousVersion)
+	tags.trace.add("Bumped minor version: %s", tags.formatVersion(version))
+	return snapshotVersion(version, tags)
 }
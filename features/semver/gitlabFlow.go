@@ -1,29 +1,50 @@
 package semver
 
 import (
-	"fmt"
 	"github.com/sotomskir/goops/gitService"
 )
 
-type gitlabFlow struct{}
+// gitlabFlow implements versioning for Gitlab flow with stable branches e.g. 1.4-stable.
+// Stable branch naming is configured with GOOPSC_SEMVER_GITLAB_STABLE_BRANCH pattern.
+type gitlabFlow struct {
+	stableBranch branchPattern
+}
 
-func (gitlabFlow) getSemanticVersion() string {
-	headTag := gitService.GetHeadTag()
-	if headTag != "" {
-		return headTag
-	}
-	previousTag := gitService.GetPreviousTag()
-	var version string
-	if previousTag == "" {
-		previousTag = "0.0.0"
+func (g gitlabFlow) getSemanticVersion(tags tagScope) (Version, error) {
+	if headVersion, tagged := tags.headVersion(); tagged {
+		return headVersion, nil
 	}
-	if isStableBranch(gitService.GetCurrentBranchName()) {
-		version = getVersionForStableBranch(previousTag)
+	previousVersion, _ := tags.previousVersion()
+	var version Version
+	branch := gitService.GetCurrentBranchName()
+	if major, minor, ok := g.stableBranch.match(branch); ok {
+		tags.trace.add("Branch: %s is stable branch", branch)
+		version = g.stableBranchVersion(previousVersion, major, minor, tags.trace)
 	} else {
-		version = bumpMinorVersion(previousTag)
-		if stableBranchExists(version) {
+		tags.trace.add("Branch: %s is not stable branch", branch)
+		version = bumpMinorVersion(previousVersion)
+		tags.trace.add("Bumped minor version: %s", tags.formatVersion(version))
+		stableBranch := g.stableBranch.format(version.Major, version.Minor)
+		if gitService.RemoteBranchExists(stableBranch) {
+			tags.trace.add("Stable branch: %s exists", stableBranch)
 			version = bumpMinorVersion(version)
+			tags.trace.add("Bumped minor version: %s", tags.formatVersion(version))
+		} else {
+			tags.trace.add("Stable branch: %s does not exist", stableBranch)
 		}
 	}
-	return fmt.Sprintf("%s-SNAPSHOT", version)
+	return snapshotVersion(version, tags)
+}
+
+// stableBranchVersion bumps patch of previous version when it matches stable branch version,
+// otherwise version from branch name with patch 0 is returned.
+func (g gitlabFlow) stableBranchVersion(previousVersion Version, major int, minor int, trace *Trace) Version {
+	if previousVersion.Major == major && previousVersion.Minor == minor {
+		version := bumpPatchVersion(previousVersion)
+		trace.add("Previous version: %s matches branch version: %d.%d, bumped patch version: %s", previousVersion, major, minor, version)
+		return version
+	}
+	version := Version{Major: major, Minor: minor}
+	trace.add("Previous version: %s does not match branch version: %d.%d, using version: %s", previousVersion, major, minor, version)
+	return version
 }
@@ -8,7 +8,6 @@ import (
 	"github.com/spf13/viper"
 	"os"
 	"regexp"
-	"strconv"
 	"strings"
 )
 
@@ -18,138 +17,299 @@ const (
 	GoopscSemverStrategy   = "GOOPSC_SEMVER_STRATEGY"
 	GoopscSemverSaveExport = "GOOPSC_SAVE_EXPORT"
 
+	GoopscSemverTagPrefix          = "GOOPSC_SEMVER_TAG_PREFIX"
+	GoopscSemverComponents         = "GOOPSC_SEMVER_COMPONENTS"
+	GoopscSemverCalverFormat       = "GOOPSC_SEMVER_CALVER_FORMAT"
+	GoopscSemverSnapshotTemplate   = "GOOPSC_SEMVER_SNAPSHOT_TEMPLATE"
+	GoopscSemverGitFlowMaster      = "GOOPSC_SEMVER_GIT_FLOW_MASTER"
+	GoopscSemverGitFlowDevelop     = "GOOPSC_SEMVER_GIT_FLOW_DEVELOP"
+	GoopscSemverGitFlowFeature     = "GOOPSC_SEMVER_GIT_FLOW_FEATURE"
+	GoopscSemverGitFlowRelease     = "GOOPSC_SEMVER_GIT_FLOW_RELEASE"
+	GoopscSemverGitFlowHotfix      = "GOOPSC_SEMVER_GIT_FLOW_HOTFIX"
+	GoopscSemverGitFlowSupport     = "GOOPSC_SEMVER_GIT_FLOW_SUPPORT"
+	GoopscSemverTrunkReleaseBranch = "GOOPSC_SEMVER_TRUNK_RELEASE_BRANCH"
+	GoopscSemverGitlabStableBranch = "GOOPSC_SEMVER_GITLAB_STABLE_BRANCH"
+
 	// Output variables
-	GoopsSemver           = "GOOPS_SEMVER"
-	GoopsSemverRelease    = "GOOPS_SEMVER_RELEASE"
-	GoopsSemverMajor      = "GOOPS_SEMVER_MAJOR"
-	GoopsSemverMinor      = "GOOPS_SEMVER_MINOR"
-	GoopsSemverPatch      = "GOOPS_SEMVER_PATCH"
+	GoopsSemver        = "GOOPS_SEMVER"
+	GoopsSemverRelease = "GOOPS_SEMVER_RELEASE"
+	GoopsSemverMajor   = "GOOPS_SEMVER_MAJOR"
+	GoopsSemverMinor   = "GOOPS_SEMVER_MINOR"
+	GoopsSemverPatch   = "GOOPS_SEMVER_PATCH"
+
+	GoopsSemverTag      = "GOOPS_SEMVER_TAG"
+	GoopsSemverDocker   = "GOOPS_SEMVER_DOCKER"
+	GoopsSemverBranch   = "GOOPS_SEMVER_BRANCH"
+	GoopsSemverDistance = "GOOPS_SEMVER_DISTANCE"
+	GoopsSemverSha      = "GOOPS_SEMVER_SHA"
+	GoopsSemverShortSha = "GOOPS_SEMVER_SHORT_SHA"
 
 	// Configuration options
 	GithubFlowStrategy    = "github-flow"
 	GitlabFlowStrategy    = "gitlab-flow"
 	GitFlowBranchStrategy = "git-flow-branch"
+
+	ConventionalCommitsStrategy = "conventional-commits"
+	CalverStrategy              = "calver"
+	GitFlowStrategy             = "git-flow"
+	TrunkBasedStrategy          = "trunk-based"
 )
 
 func setDefaults() {
 	viper.SetDefault(GoopscSemver, "false")
 	viper.SetDefault(GoopscSemverSaveExport, "true")
 	viper.SetDefault(GoopscSemverStrategy, GithubFlowStrategy)
+	viper.SetDefault(GoopscSemverTagPrefix, "")
+	viper.SetDefault(GoopscSemverCalverFormat, "YYYY.0M.MICRO")
+	viper.SetDefault(GoopscSemverSnapshotTemplate, "{{.Next}}-SNAPSHOT")
+	viper.SetDefault(GoopscSemverGitFlowMaster, "master")
+	viper.SetDefault(GoopscSemverGitFlowDevelop, "develop")
+	viper.SetDefault(GoopscSemverGitFlowFeature, "feature/")
+	viper.SetDefault(GoopscSemverGitFlowRelease, "release/")
+	viper.SetDefault(GoopscSemverGitFlowHotfix, "hotfix/")
+	viper.SetDefault(GoopscSemverGitFlowSupport, "support/")
+	viper.SetDefault(GoopscSemverTrunkReleaseBranch, "release/{major}.{minor}")
+	viper.SetDefault(GoopscSemverGitlabStableBranch, "{major}.{minor}-stable")
 }
 
 type strategy interface {
-	getSemanticVersion() string
+	getSemanticVersion(tags tagScope) (Version, error)
 }
 
 type Semver struct {
-	strategy strategy
+	name         string
+	strategyName string
+	strategy     strategy
+	tags         tagScope
 }
 
 func New() Semver {
 	setDefaults()
 	var strategy strategy
+	tags := tagScope{prefix: viper.GetString(GoopscSemverTagPrefix)}
 	switch viper.GetString(GoopscSemverStrategy) {
 	case GithubFlowStrategy:
 		strategy = githubFlow{}
 	case GitlabFlowStrategy:
-		strategy = gitlabFlow{}
+		stableBranch, err := newBranchPattern(viper.GetString(GoopscSemverGitlabStableBranch))
+		if err != nil {
+			logrus.Errorln(err)
+			os.Exit(1)
+		}
+		strategy = gitlabFlow{stableBranch: stableBranch}
 	case GitFlowBranchStrategy:
 		strategy = gitFlowBranch{}
+	case ConventionalCommitsStrategy:
+		strategy = conventionalCommits{}
+	case GitFlowStrategy:
+		strategy = newGitFlow()
+	case TrunkBasedStrategy:
+		releaseBranch, err := newBranchPattern(viper.GetString(GoopscSemverTrunkReleaseBranch))
+		if err != nil {
+			logrus.Errorln(err)
+			os.Exit(1)
+		}
+		strategy = trunkBased{releaseBranch: releaseBranch}
+	case CalverStrategy:
+		format, err := newCalverFormat(viper.GetString(GoopscSemverCalverFormat))
+		if err != nil {
+			logrus.Errorln(err)
+			os.Exit(1)
+		}
+		strategy = calver{format: format}
+		tags.format = format
 	default:
 		logrus.Errorf("Unexpected strategy: %s\n", viper.GetString(GoopscSemverStrategy))
 		os.Exit(1)
 	}
-	return Semver{strategy: strategy}
+	return Semver{strategyName: viper.GetString(GoopscSemverStrategy), strategy: strategy, tags: tags}
 }
 
-func (o *Semver) GetVersion() string {
+// Name returns component name or empty string for repository wide version.
+func (o *Semver) Name() string {
+	return o.name
+}
+
+func (o *Semver) GetVersion() (string, error) {
 	if utils.IsDisabled(GoopscSemver) {
-		return ""
+		return "", nil
+	}
+	version, err := o.getSemanticVersion()
+	if err != nil {
+		return "", err
 	}
-	version := o.strategy.getSemanticVersion()
-	major, minor, patch, _ := splitSemver(version)
 	if utils.IsEnabled(GoopscSemverSaveExport) {
-		utils.SaveExportString(GoopsSemver, version)
-		utils.SaveExportString(GoopsSemverRelease, strings.Replace(version, "-SNAPSHOT", "", 1))
-		utils.SaveExportInt(GoopsSemverMajor, major)
-		utils.SaveExportInt(GoopsSemverMinor, minor)
-		utils.SaveExportInt(GoopsSemverPatch, patch)
+		if err := o.saveExport(version); err != nil {
+			return "", err
+		}
 	}
-	return version
-}
-
-func bumpMinorVersion(version string) string {
-	major, minor, _, identifier := splitSemver(version)
-	return fmt.Sprintf("%d.%d.%d%s", major, minor+1, 0, identifier)
+	return o.tags.formatVersion(version), nil
 }
 
-func bumpPatchVersion(version string) string {
-	major, minor, patch, identifier := splitSemver(version)
-	return fmt.Sprintf("%d.%d.%d%s", major, minor, patch+1, identifier)
+// Version returns version computed by strategy without exporting output variables.
+func (o *Semver) Version() (string, error) {
+	version, err := o.getSemanticVersion()
+	if err != nil {
+		return "", err
+	}
+	return o.tags.formatVersion(version), nil
 }
 
-func splitSemver(version string) (int, int, int, string) {
-	splited := strings.Split(version, ".")
-	if len(splited) < 3 {
-		logrus.Fatalf("Invalid semver format: %s\n", version)
-	}
-	major, err := strconv.Atoi(splited[0])
+// Explain computes version the same way as GetVersion and returns decision path taken by strategy.
+// Output variables are not exported.
+func (o *Semver) Explain() (*Trace, error) {
+	trace := &Trace{Strategy: o.strategyName, Component: o.name}
+	s := *o
+	s.tags.trace = trace
+	version, err := s.getSemanticVersion()
 	if err != nil {
-		logrus.Fatalf("Error converting major version: %s to int\n", splited[0])
+		return trace, err
 	}
-	minor, err := strconv.Atoi(splited[1])
+	trace.Version = s.tags.formatVersion(version)
+	return trace, nil
+}
+
+// maxDockerTagLength is maximum length of Docker image tag
+const maxDockerTagLength = 128
+
+func (o *Semver) saveExport(version Version) error {
+	s := snapshot{next: version.Release(), tags: o.tags}
+	distance, err := s.Distance()
 	if err != nil {
-		logrus.Fatalf("Error converting minor version: %s to int\n", splited[1])
+		return err
 	}
-	splitedPatch := strings.Split(splited[2], "-")
-	patch, err := strconv.Atoi(splitedPatch[0])
+	sha, err := s.SHA()
 	if err != nil {
-		logrus.Fatalf("Error converting patch version: %s to int\n", splitedPatch[0])
+		return err
 	}
-	identifier := strings.Join(splitedPatch[1:], "-")
-	if identifier != "" {
-		identifier = "-" + identifier
+	shortSha, _ := s.ShortSHA()
+	dockerTag := strings.Replace(o.tags.formatVersion(version), "+", "_", -1)
+	if len(dockerTag) > maxDockerTagLength {
+		dockerTag = dockerTag[:maxDockerTagLength]
 	}
-	return major, minor, patch, identifier
+	utils.SaveExportString(o.variable(GoopsSemver), o.tags.formatVersion(version))
+	utils.SaveExportString(o.variable(GoopsSemverRelease), o.tags.formatVersion(version.Release()))
+	utils.SaveExportInt(o.variable(GoopsSemverMajor), version.Major)
+	utils.SaveExportInt(o.variable(GoopsSemverMinor), version.Minor)
+	utils.SaveExportInt(o.variable(GoopsSemverPatch), version.Patch)
+	utils.SaveExportString(o.variable(GoopsSemverTag), o.tags.tagName(version.Release()))
+	utils.SaveExportString(o.variable(GoopsSemverDocker), dockerTag)
+	utils.SaveExportString(o.variable(GoopsSemverBranch), s.Branch())
+	utils.SaveExportInt(o.variable(GoopsSemverDistance), distance)
+	utils.SaveExportString(o.variable(GoopsSemverSha), sha)
+	utils.SaveExportString(o.variable(GoopsSemverShortSha), shortSha)
+	return nil
+}
+
+func (o *Semver) getSemanticVersion() (Version, error) {
+	version, _, err := o.computeVersion()
+	return version, err
 }
 
-func getVersionForStableBranch(previousTag string) string {
-	if versionMatchBranchName(previousTag, gitService.GetCurrentBranchName()) {
-		return bumpPatchVersion(previousTag)
+// computeVersion returns version computed by strategy. When component paths have no changes since previous tag,
+// previous version is returned together with name of that tag, otherwise second value is empty.
+func (o *Semver) computeVersion() (Version, string, error) {
+	o.tags.build = &buildInfo{}
+	if len(o.tags.paths) > 0 {
+		previousVersion, previousTag := o.tags.previousVersion()
+		if previousTag != "" {
+			changed, err := o.tags.changedSince(previousTag)
+			if err != nil {
+				return Version{}, "", err
+			}
+			if !changed {
+				o.tags.trace.add("No changes in %v since %s, using previous version", o.tags.paths, previousTag)
+				return previousVersion, previousTag, nil
+			}
+			o.tags.trace.add("Paths %v changed since %s", o.tags.paths, previousTag)
+		}
 	}
-	return getVersionFromBranchName(gitService.GetCurrentBranchName())
+	version, err := o.strategy.getSemanticVersion(o.tags)
+	return version, "", err
 }
 
-func getVersionFromBranchName(branch string) string {
-	if isStableBranch(branch) {
-		regex := regexp.MustCompile("^(.*)-stable")
-		match := findMatch(regex, branch)
-		return fmt.Sprintf("%s.0", match[1])
+// GetReleaseVersion returns version and tag name for next release. Bump level is one of major, minor, patch,
+// when empty version is computed by strategy. Error is returned when HEAD is already tagged
+// or when component paths have no changes since previous tag.
+func (o *Semver) GetReleaseVersion(level string) (string, string, error) {
+	if headVersion, tagged := o.tags.headVersion(); tagged {
+		return "", "", fmt.Errorf("HEAD is already tagged with version: %s", o.tags.tagName(headVersion))
 	}
-	regex := regexp.MustCompile("(\\d+\\.\\d+\\.\\d+)")
-	match := findMatch(regex, branch)
-	return match[1]
+	var version Version
+	switch level {
+	case "":
+		next, unchangedTag, err := o.computeVersion()
+		if err != nil {
+			return "", "", err
+		}
+		if unchangedTag != "" {
+			return "", "", fmt.Errorf("no changes in %v since %s, nothing to release", o.tags.paths, unchangedTag)
+		}
+		version = next.Release()
+	default:
+		b, err := parseBump(level)
+		if err != nil {
+			return "", "", err
+		}
+		previousVersion, _ := o.tags.previousVersion()
+		version = b.apply(previousVersion.Release())
+	}
+	return o.tags.formatVersion(version), o.tags.tagName(version), nil
 }
 
-func findMatch(regex *regexp.Regexp, branch string) []string {
-	match := regex.FindStringSubmatch(branch)
-	if len(match) < 2 {
-		logrus.Fatalf("Version not found in branch name: %s\n", branch)
+// PreviousTag returns nearest tag in scope of o or empty string when there are no such tags.
+func (o *Semver) PreviousTag() string {
+	return o.tags.previousTag()
+}
+
+// GetHeadVersion returns version and name of tag pointing at HEAD. Last value is false when HEAD is not tagged.
+func (o *Semver) GetHeadVersion() (string, string, bool) {
+	version, tagged := o.tags.headVersion()
+	if !tagged {
+		return "", "", false
 	}
-	return match
+	return o.tags.formatVersion(version), o.tags.tagName(version), true
 }
 
-func versionMatchBranchName(version string, branch string) bool {
-	regex := regexp.MustCompile("(\\d+\\.\\d+)")
-	match := findMatch(regex, branch)
-	match2 := findMatch(regex, version)
-	return match[1] == match2[1]
+// GetCommits returns commits since previous tag, limited to component paths when set.
+// When HEAD is tagged commits since tag preceding HEAD tag are returned.
+func (o *Semver) GetCommits() ([]gitService.Commit, error) {
+	return gitService.GetCommits(o.tags.previousReleaseTag(), o.tags.paths...)
 }
 
-func isStableBranch(branch string) bool {
-	match, _ := regexp.MatchString("^.*-stable$", branch)
-	match2, _ := regexp.MatchString("^.*-stable$", viper.GetString("CI_COMMIT_REF_NAME"))
-	return match || match2
+func bumpMajorVersion(version Version) Version {
+	version.Major++
+	version.Minor = 0
+	version.Patch = 0
+	return version
+}
+
+func bumpMinorVersion(version Version) Version {
+	version.Minor++
+	version.Patch = 0
+	return version
+}
+
+func bumpPatchVersion(version Version) Version {
+	version.Patch++
+	return version
+}
+
+func getVersionFromBranchName(branch string) (Version, error) {
+	regex := regexp.MustCompile("(\\d+\\.\\d+\\.\\d+)")
+	match, err := findMatch(regex, branch)
+	if err != nil {
+		return Version{}, err
+	}
+	return Parse(match[1])
+}
+
+func findMatch(regex *regexp.Regexp, branch string) ([]string, error) {
+	match := regex.FindStringSubmatch(branch)
+	if len(match) < 2 {
+		return nil, fmt.Errorf("version not found in branch name: %s", branch)
+	}
+	return match, nil
 }
 
 func isReleaseOrHotfixBranch(branch string) bool {
@@ -157,8 +317,3 @@ func isReleaseOrHotfixBranch(branch string) bool {
 	match2, _ := regexp.MatchString("^release.*$", branch)
 	return match || match2
 }
-
-func stableBranchExists(version string) bool {
-	major, minor, _, _ := splitSemver(version)
-	return gitService.StableBranchExists(major, minor)
-}
@@ -7,7 +7,11 @@ import (
 	"github.com/sotomskir/goops/gitService"
 	"github.com/sotomskir/goops/mockExecService"
 	"github.com/spf13/viper"
+	"io/ioutil"
+	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestBumpMinorVersion(t *testing.T) {
@@ -23,7 +27,7 @@ func TestBumpMinorVersion(t *testing.T) {
 	}
 
 	for _, table := range tables {
-		actual := bumpMinorVersion(table.version)
+		actual := bumpMinorVersion(MustParse(table.version)).String()
 		if actual != table.expected {
 			t.Errorf("Version is invalid for input: '%s', got: '%s', want: '%s'", table.version, actual, table.expected)
 		}
@@ -43,7 +47,7 @@ func TestBumpPatchVersion(t *testing.T) {
 	}
 
 	for _, table := range tables {
-		actual := bumpPatchVersion(table.version)
+		actual := bumpPatchVersion(MustParse(table.version)).String()
 		if actual != table.expected {
 			t.Errorf("Version is invalid for input: '%s', got: '%s', want: '%s'", table.version, actual, table.expected)
 		}
@@ -51,30 +55,52 @@ func TestBumpPatchVersion(t *testing.T) {
 }
 
 func TestIsStableBranch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
 	tables := []struct {
+		pattern  string
 		branch   string
 		expected bool
 	}{
-		{"master", false},
-		{"1.1-stable", true},
-		{"112.346-stable", true},
-		{"64-123-stable", true},
-		{"hotfix-4.2.311", false},
+		{"{major}.{minor}-stable", "master", false},
+		{"{major}.{minor}-stable", "1.1-stable", true},
+		{"{major}.{minor}-stable", "112.346-stable", true},
+		{"{major}.{minor}-stable", "64-123-stable", false},
+		{"{major}.{minor}-stable", "hotfix-4.2.311", false},
+		{"stable/{major}.{minor}", "stable/1.1", true},
+		{"stable/{major}.{minor}", "1.1-stable", false},
 	}
 
 	for _, table := range tables {
-		actual := isStableBranch(table.branch)
+		_, _, actual := mustBranchPattern(t, table.pattern).match(table.branch)
 		if actual != table.expected {
-			t.Errorf("branch: %s, got: %t, want: %t.", table.branch, actual, table.expected)
+			t.Errorf("pattern: %s, branch: %s, got: %t, want: %t.", table.pattern, table.branch, actual, table.expected)
 		}
 	}
 
+	// CI servers checkout detached HEAD, branch is read from CI_COMMIT_REF_NAME
 	viper.Set("CI_COMMIT_REF_NAME", "6.7-stable")
-	actual := isStableBranch("da7f8adfy7asfd7")
-	if actual != true {
-		t.Errorf("branch: %s, got: %t, want: %t.", "da7f8adfy7asfd7", actual, true)
+	defer viper.Set("CI_COMMIT_REF_NAME", "")
+	mockIService := mock_execService.NewMockIService(ctrl)
+	mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return("", nil).AnyTimes()
+	mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly").Return("6.7.1", nil).AnyTimes()
+	mockIService.EXPECT().Exec("git rev-parse --abbrev-ref HEAD").Return("HEAD", nil).AnyTimes()
+	gitService.Initialize(mockIService)
+	viper.Set(GoopscSemverStrategy, GitlabFlowStrategy)
+	s := New()
+	actual, err := s.Version()
+	if err != nil || actual != "6.7.2-SNAPSHOT" {
+		t.Errorf("got: %s, err: %v, want: %s.", actual, err, "6.7.2-SNAPSHOT")
 	}
-	viper.Set("CI_COMMIT_REF_NAME", "")
+}
+
+func mustBranchPattern(t *testing.T, pattern string) branchPattern {
+	p, err := newBranchPattern(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
 }
 
 func TestGetVersionFromBranchName(t *testing.T) {
@@ -82,59 +108,95 @@ func TestGetVersionFromBranchName(t *testing.T) {
 		branch   string
 		expected string
 	}{
-		{"1.0-stable", "1.0.0"},
-		{"2.11-stable", "2.11.0"},
 		{"hotfix-3.2.311", "3.2.311"},
 		{"release-4.51.1", "4.51.1"},
 	}
 
 	for _, table := range tables {
-		actual := getVersionFromBranchName(table.branch)
-		if actual != table.expected {
+		actual, err := getVersionFromBranchName(table.branch)
+		if err != nil || actual.String() != table.expected {
 			t.Errorf("branch: %s, got: %s, want: %s.", table.branch, actual, table.expected)
 		}
 	}
 }
 
-func TestVersionMatchBranchName(t *testing.T) {
+func TestParse(tst *testing.T) {
 	tables := []struct {
-		version  string
-		branch   string
-		expected bool
+		version    string
+		major      int
+		minor      int
+		patch      int
+		prerelease string
+		build      string
+		expected   string
 	}{
-		{"1.9.0", "2.0-stable", false},
-		{"2.99.0", "2.99-stable", true},
-		{"3.0.23", "3.0-stable", true},
-		{"4.44.4", "4.45-stable", false},
+		{"1.9.0", 1, 9, 0, "", "", "1.9.0"},
+		{"2.10.99", 2, 10, 99, "", "", "2.10.99"},
+		{"3.909.1220-SNAPSHOT", 3, 909, 1220, "SNAPSHOT", "", "3.909.1220-SNAPSHOT"},
+		{"1.2.3-rc.1+build.5", 1, 2, 3, "rc.1", "build.5", "1.2.3-rc.1+build.5"},
+		{"v1.2.3", 1, 2, 3, "", "", "1.2.3"},
+		{"1.0.0+20130313144700", 1, 0, 0, "", "20130313144700", "1.0.0+20130313144700"},
+		{"1.0.0-alpha-a.b-c.0", 1, 0, 0, "alpha-a.b-c.0", "", "1.0.0-alpha-a.b-c.0"},
 	}
 
-	for _, table := range tables {
-		actual := versionMatchBranchName(table.version, table.branch)
-		if actual != table.expected {
-			t.Errorf("version: %s, branch: %s, got: %t, want: %t.", table.version, table.branch, actual, table.expected)
+	for _, t := range tables {
+		v, err := Parse(t.version)
+		if err != nil {
+			tst.Errorf("version: %s, unexpected error: %s", t.version, err)
+			continue
+		}
+		if v.Major != t.major || v.Minor != t.minor || v.Patch != t.patch ||
+			strings.Join(v.Prerelease, ".") != t.prerelease || strings.Join(v.Build, ".") != t.build || v.String() != t.expected {
+			tst.Errorf("version: %s, got: %#v, want: %s", t.version, v, t.expected)
 		}
 	}
 }
 
-func TestSplitSemver(tst *testing.T) {
-	tables := []struct {
-		version    string
-		major      int
-		minor      int
-		patch      int
-		identifier string
-	}{
-		{"1.9.0", 1, 9, 0, ""},
-		{"2.10.99", 2, 10, 99, ""},
-		{"3.909.1220-SNAPSHOT", 3, 909, 1220, "-SNAPSHOT"},
+func TestParseInvalid(t *testing.T) {
+	versions := []string{"", "1", "1.2", "1.2.3.4", "01.2.3", "1.02.3", "1.2.3-01", "1.2.3-", "1.2.3+", "1.2.3-rc..1", "nightly", "a.b.c"}
+
+	for _, version := range versions {
+		if _, err := Parse(version); err == nil {
+			t.Errorf("version: '%s', expected error", version)
+		}
 	}
+}
 
-	for _, t := range tables {
-		major, minor, patch, identifier := splitSemver(t.version)
-		if major != t.major || minor != t.minor || patch != t.patch || identifier != t.identifier {
-			tst.Errorf("version: %s, got: %d.%d.%d%s, want: %d.%d.%d%s", t.version, major, minor, patch, identifier, t.major, t.minor, t.patch, t.identifier)
+func TestCompare(t *testing.T) {
+	// Precedence order taken from SemVer 2.0.0 specification
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+		"10.0.0",
+	}
+
+	for i := range ordered {
+		for j := range ordered {
+			expected := 0
+			if i < j {
+				expected = -1
+			} else if i > j {
+				expected = 1
+			}
+			actual := MustParse(ordered[i]).Compare(MustParse(ordered[j]))
+			if actual != expected {
+				t.Errorf("compare %s with %s, got: %d, want: %d", ordered[i], ordered[j], actual, expected)
+			}
 		}
 	}
+
+	if !MustParse("1.0.0+build.1").Equal(MustParse("1.0.0+build.2")) {
+		t.Errorf("build metadata should be ignored when comparing versions")
+	}
 }
 
 func TestGetSemanticVersion(t *testing.T) {
@@ -185,14 +247,680 @@ func TestGetSemanticVersion(t *testing.T) {
 	s := New()
 	for _, table := range tables {
 		mockIService := mock_execService.NewMockIService(ctrl)
-		mockIService.EXPECT().Exec("git --no-pager tag --contains").Return(table.tag, table.error).AnyTimes()
+		mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return(table.tag, table.error).AnyTimes()
 		mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly").Return(table.previousTag, table.previousError).AnyTimes()
 		mockIService.EXPECT().Exec("git rev-parse --abbrev-ref HEAD").Return(table.branch, nil).AnyTimes()
-		mockIService.EXPECT().Exec(fmt.Sprintf("git --no-pager branch --remotes --list '*%s'", table.stableBranch)).Return(table.stableBranchReturn, nil).AnyTimes()
+		mockIService.EXPECT().Exec(fmt.Sprintf("git --no-pager branch --remotes --list */%s", table.stableBranch)).Return(table.stableBranchReturn, nil).AnyTimes()
+		gitService.Initialize(mockIService)
+		actual, err := s.GetVersion()
+		if err != nil || actual != table.expected {
+			t.Errorf("Version is invalid, got: '%s', want: '%s'\n%v.", actual, table.expected, table)
+		}
+	}
+}
+
+func TestGetSemanticVersionGitlabFlowStableBranchPattern(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tables := []struct {
+		branch             string
+		previousTag        string
+		stableBranch       string
+		stableBranchReturn string
+		expected           string
+	}{
+		{"master", "1.2.0", "stable/1.3", "", "1.3.0-SNAPSHOT"},
+		{"master", "1.2.0", "stable/1.3", "remotes/origin/stable/1.3", "1.4.0-SNAPSHOT"},
+		{"stable/1.3", "1.3.2", "", "", "1.3.3-SNAPSHOT"},
+		{"stable/1.4", "1.3.2", "", "", "1.4.0-SNAPSHOT"},
+		{"1.3-stable", "1.3.2", "stable/1.4", "", "1.4.0-SNAPSHOT"},
+	}
+
+	viper.Set(GoopscSemverSaveExport, "false")
+	viper.Set(GoopscSemverStrategy, GitlabFlowStrategy)
+	viper.Set(GoopscSemverGitlabStableBranch, "stable/{major}.{minor}")
+	defer viper.Set(GoopscSemverGitlabStableBranch, "{major}.{minor}-stable")
+	s := New()
+	for _, table := range tables {
+		mockIService := mock_execService.NewMockIService(ctrl)
+		mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return("", nil).AnyTimes()
+		mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly").Return(table.previousTag, nil).AnyTimes()
+		mockIService.EXPECT().Exec("git rev-parse --abbrev-ref HEAD").Return(table.branch, nil).AnyTimes()
+		mockIService.EXPECT().Exec(fmt.Sprintf("git --no-pager branch --remotes --list */%s", table.stableBranch)).Return(table.stableBranchReturn, nil).AnyTimes()
 		gitService.Initialize(mockIService)
-		actual := s.GetVersion()
+		actual, err := s.GetVersion()
+		if err != nil || actual != table.expected {
+			t.Errorf("Version is invalid, got: '%s', want: '%s'\n%v.", actual, table.expected, table)
+		}
+	}
+}
+
+func TestGetConventionalBump(t *testing.T) {
+	tables := []struct {
+		messages []string
+		expected bump
+	}{
+		{[]string{"fix: typo", "docs: update readme"}, patchBump},
+		{[]string{"chore(deps): bump viper"}, patchBump},
+		{[]string{"fix: typo", "feat(api): add login endpoint"}, minorBump},
+		{[]string{"feat!: drop v1 api", "fix: typo"}, majorBump},
+		{[]string{"refactor(core)!: rename config keys"}, majorBump},
+		{[]string{"feat: new config\n\nBREAKING CHANGE: old keys are not supported"}, majorBump},
+		{[]string{"Merge branch 'feature' into master", "update: feature"}, patchBump},
+		{[]string{}, patchBump},
+	}
+
+	for _, table := range tables {
+		actual := getConventionalBump(table.messages)
 		if actual != table.expected {
+			t.Errorf("messages: %q, got: %d, want: %d.", table.messages, actual, table.expected)
+		}
+	}
+}
+
+func TestParseConventionalCommit(t *testing.T) {
+	tables := []struct {
+		message  string
+		expected ConventionalCommit
+		ok       bool
+	}{
+		{"feat(api): add login endpoint", ConventionalCommit{"feat", "api", "add login endpoint", false}, true},
+		{"Fix!: drop v1 api\n\nbody", ConventionalCommit{"fix", "", "drop v1 api", true}, true},
+		{"docs: update\n\nBREAKING CHANGE: removed page", ConventionalCommit{"docs", "", "update", true}, true},
+		{"Merge branch 'feature' into master", ConventionalCommit{"", "", "Merge branch 'feature' into master", false}, false},
+	}
+
+	for _, table := range tables {
+		actual, ok := ParseConventionalCommit(table.message)
+		if actual != table.expected || ok != table.ok {
+			t.Errorf("message: %q, got: %#v %t, want: %#v %t.", table.message, actual, ok, table.expected, table.ok)
+		}
+	}
+}
+
+func TestGetSemanticVersionConventionalCommits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tables := []struct {
+		tag         string
+		previousTag string
+		log         string
+		expected    string
+	}{
+		{"1.2.3", "1.2.2", "", "1.2.3"},
+		{"", "1.2.3", "fix: typo\x1e", "1.2.4-SNAPSHOT"},
+		{"", "1.2.3", "fix: typo\x1efeat: login\x1e", "1.3.0-SNAPSHOT"},
+		{"", "1.2.3", "feat!: drop v1 api\x1e", "2.0.0-SNAPSHOT"},
+		{"", "", "feat: initial features\x1e", "0.1.0-SNAPSHOT"},
+	}
+
+	viper.Set(GoopscSemverSaveExport, "false")
+	viper.Set(GoopscSemver, "true")
+	viper.Set(GoopscSemverStrategy, ConventionalCommitsStrategy)
+	s := New()
+	for _, table := range tables {
+		logCmd := "git --no-pager log --format=%B%x1e HEAD"
+		if table.previousTag != "" {
+			logCmd = fmt.Sprintf("git --no-pager log --format=%%B%%x1e %s..HEAD", table.previousTag)
+		}
+		mockIService := mock_execService.NewMockIService(ctrl)
+		mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return(table.tag, nil).AnyTimes()
+		mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly").Return(table.previousTag, nil).AnyTimes()
+		mockIService.EXPECT().Exec(logCmd).Return(table.log, nil).AnyTimes()
+		gitService.Initialize(mockIService)
+		actual, err := s.GetVersion()
+		if err != nil || actual != table.expected {
 			t.Errorf("Version is invalid, got: '%s', want: '%s'\n%v.", actual, table.expected, table)
 		}
 	}
 }
+
+func TestGetSemanticVersionTagPrefix(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	describe := "git describe --abbrev=0 --tags --exclude nightly"
+	list := "git --no-pager tag --merged HEAD --sort=-creatordate"
+	tables := []struct {
+		prefix   string
+		headTags string
+		commands map[string]string
+		expected string
+	}{
+		// HEAD tag with prefix is used as version
+		{"v", "v1.2.3", nil, "1.2.3"},
+		// highest semver tag is used when HEAD has multiple tags, other tags are ignored
+		{"v", "api/2.0.0\nv1.2.3\nv1.10.0\nvlatest", nil, "1.10.0"},
+		// previous tag with prefix is bumped
+		{"v", "", map[string]string{describe + " --match v*": "v1.2.3"}, "1.3.0-SNAPSHOT"},
+		// tags matching prefix but not being semver are skipped
+		{"v", "", map[string]string{
+			describe + " --match v*": "vlatest",
+			list + " --list v*":      "vlatest\nv1.x\nv0.9.0\nv0.8.0",
+		}, "0.10.0-SNAPSHOT"},
+		{"v", "", map[string]string{describe + " --match v*": "vlatest", list + " --list v*": "vlatest\nv1.x"}, "0.1.0-SNAPSHOT"},
+		// no tags with prefix
+		{"api/", "v1.0.0", map[string]string{describe + " --match api/*": ""}, "0.1.0-SNAPSHOT"},
+		// tags without prefix are still supported
+		{"", "", map[string]string{describe: "release-candidate", list: "release-candidate\n2.0.0"}, "2.1.0-SNAPSHOT"},
+	}
+
+	viper.Set(GoopscSemverSaveExport, "false")
+	viper.Set(GoopscSemver, "true")
+	viper.Set(GoopscSemverStrategy, GithubFlowStrategy)
+	for _, table := range tables {
+		viper.Set(GoopscSemverTagPrefix, table.prefix)
+		s := New()
+		mockIService := mock_execService.NewMockIService(ctrl)
+		mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return(table.headTags, nil).AnyTimes()
+		for cmd, tag := range table.commands {
+			var err error
+			if tag == "" {
+				err = errors.New("no names found")
+			}
+			mockIService.EXPECT().Exec(cmd).Return(tag, err).AnyTimes()
+		}
+		gitService.Initialize(mockIService)
+		actual, err := s.GetVersion()
+		if err != nil || actual != table.expected {
+			t.Errorf("Version is invalid, got: '%s', want: '%s', err: %v\n%v.", actual, table.expected, err, table)
+		}
+	}
+	viper.Set(GoopscSemverTagPrefix, "")
+}
+
+func TestTagName(t *testing.T) {
+	tags := tagScope{prefix: "api/"}
+	if actual := tags.tagName(MustParse("1.2.3")); actual != "api/1.2.3" {
+		t.Errorf("got: '%s', want: '%s'", actual, "api/1.2.3")
+	}
+}
+
+func TestComponents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	viper.Set(GoopscSemverSaveExport, "false")
+	viper.Set(GoopscSemver, "true")
+	viper.Set(GoopscSemverStrategy, GithubFlowStrategy)
+	viper.Set(GoopscSemverComponents, []map[string]interface{}{
+		{"name": "api", "paths": []string{"services/api", "lib"}},
+		{"name": "web-ui", "tag_prefix": "web-v", "paths": []string{"services/web"}},
+		{"name": "cli"},
+	})
+	defer viper.Set(GoopscSemverComponents, nil)
+
+	mockIService := mock_execService.NewMockIService(ctrl)
+	mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return("", nil).AnyTimes()
+	mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly --match api/*").Return("api/1.4.0", nil).AnyTimes()
+	mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly --match web-v*").Return("web-v2.0.1", nil).AnyTimes()
+	mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly --match cli/*").Return("", errors.New("no names found")).AnyTimes()
+	mockIService.EXPECT().Exec("git rev-list --count api/1.4.0..HEAD -- services/api lib").Return("3", nil).AnyTimes()
+	mockIService.EXPECT().Exec("git rev-list --count web-v2.0.1..HEAD -- services/web").Return("0", nil).AnyTimes()
+	gitService.Initialize(mockIService)
+
+	s := New()
+	actual, err := s.GetComponentVersions()
+	expected := map[string]string{
+		// changed since last tag
+		"api": "1.5.0-SNAPSHOT",
+		// no changes since last tag
+		"web-ui": "2.0.1",
+		// no tags
+		"cli": "0.1.0-SNAPSHOT",
+	}
+	if err != nil || fmt.Sprint(actual) != fmt.Sprint(expected) {
+		t.Errorf("got: %v, want: %v, err: %v", actual, expected, err)
+	}
+
+	api, _ := s.Component("api")
+	if version, tag, err := api.GetReleaseVersion(""); err != nil || version != "1.5.0" || tag != "api/1.5.0" {
+		t.Errorf("got: %s %s, want: 1.5.0 api/1.5.0, err: %v", version, tag, err)
+	}
+	// release of unchanged component would create tag that already exists
+	webUi, _ := s.Component("web-ui")
+	if _, _, err := webUi.GetReleaseVersion(""); err == nil || !strings.Contains(err.Error(), "since web-v2.0.1") {
+		t.Errorf("expected no changes error, got: %v", err)
+	}
+
+	if _, err := s.Component("missing"); err == nil {
+		t.Errorf("expected error for missing component")
+	}
+}
+
+func TestVariable(t *testing.T) {
+	tables := []struct {
+		component string
+		variable  string
+		expected  string
+	}{
+		{"", GoopsSemver, "GOOPS_SEMVER"},
+		{"", GoopsSemverRelease, "GOOPS_SEMVER_RELEASE"},
+		{"api", GoopsSemver, "GOOPS_SEMVER_API"},
+		{"api", GoopsSemverRelease, "GOOPS_SEMVER_API_RELEASE"},
+		{"web-ui", GoopsSemverMajor, "GOOPS_SEMVER_WEB_UI_MAJOR"},
+		{"services/auth", GoopsSemverTag, "GOOPS_SEMVER_SERVICES_AUTH_TAG"},
+	}
+
+	for _, table := range tables {
+		s := Semver{name: table.component}
+		actual := s.variable(table.variable)
+		if actual != table.expected {
+			t.Errorf("component: %s, got: %s, want: %s", table.component, actual, table.expected)
+		}
+	}
+}
+
+func TestCalverFormat(t *testing.T) {
+	tables := []struct {
+		layout   string
+		version  string
+		expected Version
+	}{
+		{"YYYY.0M.MICRO", "2024.01.3", Version{Major: 2024, Minor: 1, Patch: 3}},
+		{"YYYY.MM.MICRO", "2024.11.0-SNAPSHOT", Version{Major: 2024, Minor: 11, Patch: 0, Prerelease: []string{"SNAPSHOT"}}},
+		{"YY.0W.MICRO", "24.07.12", Version{Major: 24, Minor: 7, Patch: 12}},
+		{"0Y.0D.MICRO", "06.09.1+build.1", Version{Major: 6, Minor: 9, Patch: 1, Build: []string{"build", "1"}}},
+	}
+
+	for _, table := range tables {
+		format, err := newCalverFormat(table.layout)
+		if err != nil {
+			t.Errorf("layout: %s, unexpected error: %s", table.layout, err)
+			continue
+		}
+		actual, err := format.parse(table.version)
+		if err != nil || fmt.Sprint(actual) != fmt.Sprint(table.expected) {
+			t.Errorf("layout: %s, version: %s, got: %#v, want: %#v, err: %v", table.layout, table.version, actual, table.expected, err)
+		}
+		if formatted := format.format(actual); formatted != table.version {
+			t.Errorf("layout: %s, got: %s, want: %s", table.layout, formatted, table.version)
+		}
+	}
+
+	for _, layout := range []string{"YYYY.MM", "YYYY.MICRO.MM", "YYYY.0Q.MICRO", "1.2.3"} {
+		if _, err := newCalverFormat(layout); err == nil {
+			t.Errorf("layout: %s, expected error", layout)
+		}
+	}
+	format, _ := newCalverFormat("YYYY.0M.MICRO")
+	if _, err := format.parse("1.2.3"); err == nil {
+		t.Errorf("expected error for version not matching layout")
+	}
+}
+
+func TestGetSemanticVersionCalver(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tables := []struct {
+		layout      string
+		date        time.Time
+		tag         string
+		previousTag string
+		expected    string
+	}{
+		{"YYYY.0M.MICRO", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), "2024.01.2", "2024.01.1", "2024.01.2"},
+		{"YYYY.0M.MICRO", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), "", "", "2024.01.0-SNAPSHOT"},
+		{"YYYY.0M.MICRO", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), "", "2024.01.4", "2024.01.5-SNAPSHOT"},
+		{"YYYY.0M.MICRO", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), "", "2024.01.4", "2024.02.0-SNAPSHOT"},
+		{"YY.0W.MICRO", time.Date(2024, 2, 14, 0, 0, 0, 0, time.UTC), "", "24.07.0", "24.07.1-SNAPSHOT"},
+		// 30 December 2024 is in first ISO week of 2025
+		{"YY.0W.MICRO", time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), "", "24.52.3", "25.01.0-SNAPSHOT"},
+		// previous tag in other format is ignored
+		{"YYYY.MM.MICRO", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), "", "1.2.3", "2024.3.0-SNAPSHOT"},
+	}
+
+	viper.Set(GoopscSemverSaveExport, "false")
+	viper.Set(GoopscSemver, "true")
+	viper.Set(GoopscSemverStrategy, CalverStrategy)
+	defer func() { now = time.Now }()
+	for _, table := range tables {
+		viper.Set(GoopscSemverCalverFormat, table.layout)
+		now = func() time.Time { return table.date }
+		mockIService := mock_execService.NewMockIService(ctrl)
+		mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return(table.tag, nil).AnyTimes()
+		mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly").Return(table.previousTag, nil).AnyTimes()
+		mockIService.EXPECT().Exec("git --no-pager tag --merged HEAD --sort=-creatordate").Return(table.previousTag, nil).AnyTimes()
+		gitService.Initialize(mockIService)
+		s := New()
+		actual, err := s.GetVersion()
+		if err != nil || actual != table.expected {
+			t.Errorf("Version is invalid, got: '%s', want: '%s', err: %v\n%v.", actual, table.expected, err, table)
+		}
+	}
+	viper.Set(GoopscSemverCalverFormat, "YYYY.0M.MICRO")
+}
+
+func TestSanitizeIdentifier(t *testing.T) {
+	tables := []struct {
+		branch   string
+		expected string
+	}{
+		{"master", "master"},
+		{"feature/ABC-123_login", "feature-ABC-123-login"},
+		{"/bugfix//#42.fix/", "bugfix-42-fix"},
+		{"", "HEAD"},
+		{"0123", "branch-0123"},
+		{"123", "123"},
+		{"0", "0"},
+		{"01-fix", "01-fix"},
+	}
+
+	for _, table := range tables {
+		actual := sanitizeIdentifier(table.branch)
+		if actual != table.expected {
+			t.Errorf("branch: %s, got: %s, want: %s", table.branch, actual, table.expected)
+		}
+	}
+}
+
+func TestSnapshotTemplate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tables := []struct {
+		template string
+		expected string
+	}{
+		{"{{.Next}}-SNAPSHOT", "1.3.0-SNAPSHOT"},
+		{"{{.Next}}-{{.Branch}}.{{.Distance}}+{{.ShortSHA}}", "1.3.0-feature-ABC-1.5+0a1b2c3"},
+		{"{{.Next}}-SNAPSHOT+{{.SHA}}", "1.3.0-SNAPSHOT+0a1b2c3d4e5f"},
+	}
+
+	viper.Set(GoopscSemverSaveExport, "false")
+	viper.Set(GoopscSemver, "true")
+	viper.Set(GoopscSemverStrategy, GithubFlowStrategy)
+	viper.Set("CI_COMMIT_REF_NAME", "feature/ABC_1")
+	defer viper.Set("CI_COMMIT_REF_NAME", "")
+	defer viper.Set(GoopscSemverSnapshotTemplate, "{{.Next}}-SNAPSHOT")
+	mockIService := mock_execService.NewMockIService(ctrl)
+	mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return("", nil).AnyTimes()
+	mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly").Return("1.2.0", nil).AnyTimes()
+	mockIService.EXPECT().Exec("git rev-list --count 1.2.0..HEAD").Return("5", nil).AnyTimes()
+	mockIService.EXPECT().Exec("git rev-parse HEAD").Return("0a1b2c3d4e5f", nil).AnyTimes()
+	gitService.Initialize(mockIService)
+	for _, table := range tables {
+		viper.Set(GoopscSemverSnapshotTemplate, table.template)
+		s := New()
+		actual, err := s.GetVersion()
+		if err != nil || actual != table.expected {
+			t.Errorf("template: %s, got: '%s', want: '%s', err: %v", table.template, actual, table.expected, err)
+		}
+	}
+
+	for _, template := range []string{"{{.Next", "{{.Unknown}}", "{{.Next}}-feature/x"} {
+		viper.Set(GoopscSemverSnapshotTemplate, template)
+		s := New()
+		if _, err := s.GetVersion(); err == nil {
+			t.Errorf("template: %s, expected error", template)
+		}
+	}
+}
+
+func TestSaveExport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dir, err := ioutil.TempDir("", "semver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	os.Chdir(dir)
+
+	branch := "feature/" + strings.Repeat("a", 150)
+	viper.Set(GoopscSemverSaveExport, "true")
+	viper.Set(GoopscSemver, "true")
+	viper.Set(GoopscSemverStrategy, GithubFlowStrategy)
+	viper.Set(GoopscSemverSnapshotTemplate, "{{.Next}}-{{.Branch}}.{{.Distance}}+{{.ShortSHA}}")
+	viper.Set("CI_COMMIT_REF_NAME", branch)
+	viper.Set("CI_COMMIT_SHA", "0a1b2c3d4e5f")
+	defer viper.Set(GoopscSemverSaveExport, "false")
+	defer viper.Set(GoopscSemverSnapshotTemplate, "{{.Next}}-SNAPSHOT")
+	defer viper.Set("CI_COMMIT_REF_NAME", "")
+	defer viper.Set("CI_COMMIT_SHA", "")
+	mockIService := mock_execService.NewMockIService(ctrl)
+	mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return("", nil).AnyTimes()
+	mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly").Return("1.2.0", nil).AnyTimes()
+	// distance is read once for snapshot template and output variables, sha is read from CI_COMMIT_SHA
+	mockIService.EXPECT().Exec("git rev-list --count 1.2.0..HEAD").Return("5", nil).Times(1)
+	gitService.Initialize(mockIService)
+
+	s := New()
+	version, err := s.GetVersion()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	content, _ := ioutil.ReadFile(".goops.env")
+	exports := strings.Split(strings.TrimSpace(string(content)), "\n")
+	expected := map[string]string{
+		GoopsSemver:         version,
+		GoopsSemverDocker:   strings.Replace(version, "+", "_", -1)[:maxDockerTagLength],
+		GoopsSemverDistance: "5",
+		GoopsSemverSha:      "0a1b2c3d4e5f",
+		GoopsSemverShortSha: "0a1b2c3",
+	}
+	for name, value := range expected {
+		if !contains(exports, fmt.Sprintf("export %s=%s", name, value)) {
+			t.Errorf("export %s=%s not found in:\n%s", name, value, content)
+		}
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGetSemanticVersionGitFlow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tables := []struct {
+		branch      string
+		tag         string
+		previousTag string
+		branches    string
+		expected    string
+	}{
+		{"master", "1.2.0", "1.1.0", "", "1.2.0"},
+		{"master", "", "1.2.0", "", "1.2.1-SNAPSHOT"},
+		{"develop", "", "1.2.0", "", "1.3.0-alpha.7"},
+		// release branch for next minor version already exists
+		{"develop", "", "1.2.0", "master\nrelease/1.3.0", "1.4.0-alpha.7"},
+		{"develop", "", "1.2.0", "release/1.3\nrelease/1.4\nrelease/next", "1.5.0-alpha.7"},
+		{"feature/login", "", "1.2.0", "release/1.30\nrelease/2.3", "1.3.0-alpha.login.7"},
+		{"develop", "", "", "", "0.1.0-alpha.7"},
+		{"feature/ABC-1_login", "", "1.2.0", "", "1.3.0-alpha.ABC-1-login.7"},
+		{"feature/0123", "", "1.2.0", "", "1.3.0-alpha.branch-0123.7"},
+		{"release/1.3.0", "", "1.2.0", "", "1.3.0-rc.7"},
+		{"release/next", "", "1.2.0", "", "1.3.0-rc.7"},
+		{"hotfix/1.2.1", "", "1.2.0", "", "1.2.1-rc.7"},
+		{"hotfix/login", "", "1.2.0", "", "1.2.1-rc.7"},
+		{"support/1.x", "", "1.2.0", "", "1.2.1-SNAPSHOT"},
+		{"bugfix/login", "", "1.2.0", "", "1.3.0-SNAPSHOT"},
+	}
+
+	viper.Set(GoopscSemverSaveExport, "false")
+	viper.Set(GoopscSemver, "true")
+	viper.Set(GoopscSemverStrategy, GitFlowStrategy)
+	defer viper.Set("CI_COMMIT_REF_NAME", "")
+	s := New()
+	for _, table := range tables {
+		viper.Set("CI_COMMIT_REF_NAME", table.branch)
+		count := "git rev-list --count HEAD"
+		if table.previousTag != "" {
+			count = fmt.Sprintf("git rev-list --count %s..HEAD", table.previousTag)
+		}
+		mockIService := mock_execService.NewMockIService(ctrl)
+		mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return(table.tag, nil).AnyTimes()
+		mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly").Return(table.previousTag, nil).AnyTimes()
+		mockIService.EXPECT().Exec(count).Return("7", nil).AnyTimes()
+		mockIService.EXPECT().Exec("git --no-pager branch --remotes --format=%(refname:lstrip=3)").Return(table.branches, nil).AnyTimes()
+		gitService.Initialize(mockIService)
+		actual, err := s.GetVersion()
+		if err != nil || actual != table.expected {
+			t.Errorf("Version is invalid, got: '%s', want: '%s', err: %v\n%v.", actual, table.expected, err, table)
+		}
+	}
+}
+
+func TestGitFlowBranchPrefixes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	viper.Set(GoopscSemverGitFlowDevelop, "dev")
+	viper.Set(GoopscSemverGitFlowRelease, "rel-")
+	defer viper.Set(GoopscSemverGitFlowDevelop, "develop")
+	defer viper.Set(GoopscSemverGitFlowRelease, "release/")
+	defer viper.Set("CI_COMMIT_REF_NAME", "")
+	g := newGitFlow()
+
+	mockIService := mock_execService.NewMockIService(ctrl)
+	mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return("", nil).AnyTimes()
+	mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly").Return("2.0.0", nil).AnyTimes()
+	mockIService.EXPECT().Exec("git rev-list --count 2.0.0..HEAD").Return("3", nil).AnyTimes()
+	mockIService.EXPECT().Exec("git --no-pager branch --remotes --format=%(refname:lstrip=3)").Return("release/2.1.0\nrel-2.1", nil).AnyTimes()
+	gitService.Initialize(mockIService)
+
+	for branch, expected := range map[string]string{"dev": "2.2.0-alpha.3", "rel-2.1.0": "2.1.0-rc.3"} {
+		viper.Set("CI_COMMIT_REF_NAME", branch)
+		actual, err := g.getSemanticVersion(tagScope{})
+		if err != nil || actual.String() != expected {
+			t.Errorf("branch: %s, got: '%s', want: '%s', err: %v", branch, actual, expected, err)
+		}
+	}
+}
+
+func TestBranchPattern(t *testing.T) {
+	tables := []struct {
+		pattern string
+		branch  string
+		major   int
+		minor   int
+		match   bool
+	}{
+		{"release/{major}.{minor}", "release/1.4", 1, 4, true},
+		{"release/{major}.{minor}", "release/1.4.1", 0, 0, false},
+		{"release/{major}.{minor}", "feature/release/1.4", 0, 0, false},
+		{"{major}.{minor}-stable", "12.34-stable", 12, 34, true},
+		{"v{major}-{minor}.x", "v2-0.x", 2, 0, true},
+		{"v{major}-{minor}.x", "v2-0ax", 0, 0, false},
+	}
+
+	for _, table := range tables {
+		pattern, err := newBranchPattern(table.pattern)
+		if err != nil {
+			t.Errorf("pattern: %s, unexpected error: %s", table.pattern, err)
+			continue
+		}
+		major, minor, match := pattern.match(table.branch)
+		if major != table.major || minor != table.minor || match != table.match {
+			t.Errorf("pattern: %s, branch: %s, got: %d.%d %t, want: %d.%d %t", table.pattern, table.branch, major, minor, match, table.major, table.minor, table.match)
+		}
+		if table.match && pattern.format(major, minor) != table.branch {
+			t.Errorf("pattern: %s, format: %d.%d, got: %s, want: %s", table.pattern, major, minor, pattern.format(major, minor), table.branch)
+		}
+	}
+
+	if _, err := newBranchPattern("release/{major}"); err == nil {
+		t.Errorf("expected error for pattern without {minor}")
+	}
+}
+
+func TestGetSemanticVersionTrunkBased(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tables := []struct {
+		branch         string
+		tag            string
+		previousTag    string
+		releaseTag     string
+		remoteBranches string
+		expected       string
+	}{
+		{"main", "", "", "", "main", "0.1.0-SNAPSHOT"},
+		{"main", "", "1.2.0", "", "main\nrelease/1.2", "1.3.0-SNAPSHOT"},
+		// release tags are not reachable from main
+		{"main", "", "1.2.0", "", "main\nrelease/1.3\nrelease/1.4", "1.5.0-SNAPSHOT"},
+		{"feature/login", "", "", "", "main\nrelease/1.4", "1.5.0-SNAPSHOT"},
+		{"release/1.4", "", "1.2.0", "1.4.2", "", "1.4.3-SNAPSHOT"},
+		{"release/1.4", "", "1.2.0", "", "", "1.4.0-SNAPSHOT"},
+		{"release/1.4", "1.4.3", "1.4.2", "1.4.2", "", "1.4.3"},
+	}
+
+	viper.Set(GoopscSemverSaveExport, "false")
+	viper.Set(GoopscSemver, "true")
+	viper.Set(GoopscSemverStrategy, TrunkBasedStrategy)
+	defer viper.Set("CI_COMMIT_REF_NAME", "")
+	s := New()
+	for _, table := range tables {
+		viper.Set("CI_COMMIT_REF_NAME", table.branch)
+		var releaseTagErr error
+		if table.releaseTag == "" {
+			releaseTagErr = errors.New("no names found")
+		}
+		mockIService := mock_execService.NewMockIService(ctrl)
+		mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return(table.tag, nil).AnyTimes()
+		mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly").Return(table.previousTag, nil).AnyTimes()
+		mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly --match 1.4.*").Return(table.releaseTag, releaseTagErr).AnyTimes()
+		mockIService.EXPECT().Exec("git --no-pager branch --remotes --format=%(refname:lstrip=3)").Return(table.remoteBranches, nil).AnyTimes()
+		gitService.Initialize(mockIService)
+		actual, err := s.GetVersion()
+		if err != nil || actual != table.expected {
+			t.Errorf("Version is invalid, got: '%s', want: '%s', err: %v\n%v.", actual, table.expected, err, table)
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	viper.Set(GoopscSemverStrategy, GitlabFlowStrategy)
+	s := New()
+	mockIService := mock_execService.NewMockIService(ctrl)
+	mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return("", nil).AnyTimes()
+	mockIService.EXPECT().Exec("git describe --abbrev=0 --tags --exclude nightly").Return("4.44.444", nil).AnyTimes()
+	mockIService.EXPECT().Exec("git rev-parse --abbrev-ref HEAD").Return("master", nil).AnyTimes()
+	mockIService.EXPECT().Exec("git --no-pager branch --remotes --list */4.45-stable").Return("remotes/origin/4.45-stable", nil).AnyTimes()
+	mockIService.EXPECT().Exec("git --no-pager branch --remotes --list */4.46-stable").Return("", nil).AnyTimes()
+	gitService.Initialize(mockIService)
+
+	trace, err := s.Explain()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []string{
+		"HEAD is not tagged",
+		"Previous tag: 4.44.444",
+		"Branch: master is not stable branch",
+		"Bumped minor version: 4.45.0",
+		"Stable branch: 4.45-stable exists",
+		"Bumped minor version: 4.46.0",
+		"Next version: 4.46.0, snapshot template: '{{.Next}}-SNAPSHOT'",
+	}
+	if trace.Version != "4.46.0-SNAPSHOT" || trace.Strategy != GitlabFlowStrategy {
+		t.Errorf("got: %s (%s), want: 4.46.0-SNAPSHOT (%s)", trace.Version, trace.Strategy, GitlabFlowStrategy)
+	}
+	if strings.Join(trace.Steps, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Steps are invalid, got:\n%s\nwant:\n%s", strings.Join(trace.Steps, "\n"), strings.Join(expected, "\n"))
+	}
+	if !strings.HasPrefix(trace.String(), "Version: 4.46.0-SNAPSHOT (strategy: gitlab-flow)\n  1. HEAD is not tagged\n") {
+		t.Errorf("Text output is invalid, got:\n%s", trace)
+	}
+	out, err := TracesToJSON([]*Trace{trace})
+	if err != nil || !strings.Contains(out, "\"version\": \"4.46.0-SNAPSHOT\"") || !strings.Contains(out, "\"strategy\": \"gitlab-flow\"") {
+		t.Errorf("JSON output is invalid, got: %s, err: %v", out, err)
+	}
+}
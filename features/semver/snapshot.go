@@ -0,0 +1,116 @@
+package semver
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/sotomskir/goops/gitService"
+	"github.com/spf13/viper"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+var invalidIdentifierRegex = regexp.MustCompile("[^0-9A-Za-z-]+")
+
+var numericIdentifierRegex = regexp.MustCompile("^0[0-9]+$")
+
+// shaVariables are set to HEAD commit sha by GitLab CI, GitHub Actions, Jenkins and Travis CI
+var shaVariables = []string{"CI_COMMIT_SHA", "GITHUB_SHA", "GIT_COMMIT", "TRAVIS_COMMIT"}
+
+// buildInfo caches values read from git during version computation, so they are read only when used
+// by snapshot template or output variables, and at most once.
+type buildInfo struct {
+	distance     int
+	distanceRead bool
+	sha          string
+}
+
+// snapshot holds data available in snapshot version template. Values are read from git only when used by template.
+type snapshot struct {
+	next Version
+	tags tagScope
+}
+
+// Next returns next release version
+func (s snapshot) Next() string {
+	return s.tags.formatVersion(s.next)
+}
+
+// Branch returns current branch name sanitized to match semver identifier and Docker tag rules.
+func (s snapshot) Branch() string {
+	return sanitizeIdentifier(gitService.GetCurrentBranchName())
+}
+
+// Distance returns number of commits since previous tag
+func (s snapshot) Distance() (int, error) {
+	if b := s.tags.build; b != nil && b.distanceRead {
+		return b.distance, nil
+	}
+	distance, err := gitService.GetCommitCount(s.tags.previousTag(), s.tags.paths...)
+	if err == nil && s.tags.build != nil {
+		s.tags.build.distance, s.tags.build.distanceRead = distance, true
+	}
+	return distance, err
+}
+
+// SHA returns HEAD commit sha set by CI server, or read from git when not set.
+func (s snapshot) SHA() (string, error) {
+	if b := s.tags.build; b != nil && b.sha != "" {
+		return b.sha, nil
+	}
+	sha := ""
+	for _, variable := range shaVariables {
+		if sha = viper.GetString(variable); sha != "" {
+			break
+		}
+	}
+	if sha == "" {
+		var err error
+		if sha, err = gitService.GetHeadSha(); err != nil {
+			return "", err
+		}
+	}
+	if s.tags.build != nil {
+		s.tags.build.sha = sha
+	}
+	return sha, nil
+}
+
+func (s snapshot) ShortSHA() (string, error) {
+	sha, err := s.SHA()
+	if len(sha) > 7 {
+		sha = sha[:7]
+	}
+	return sha, err
+}
+
+// snapshotVersion renders snapshot version of next release using GOOPSC_SEMVER_SNAPSHOT_TEMPLATE.
+func snapshotVersion(next Version, tags tagScope) (Version, error) {
+	tmpl, err := template.New("snapshot").Option("missingkey=error").Parse(viper.GetString(GoopscSemverSnapshotTemplate))
+	if err != nil {
+		return Version{}, fmt.Errorf("invalid %s: %s", GoopscSemverSnapshotTemplate, err)
+	}
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, snapshot{next: next, tags: tags}); err != nil {
+		return Version{}, fmt.Errorf("invalid %s: %s", GoopscSemverSnapshotTemplate, err)
+	}
+	version, err := tags.parseVersion(buffer.String())
+	if err != nil {
+		return Version{}, fmt.Errorf("invalid %s: %s", GoopscSemverSnapshotTemplate, err)
+	}
+	tags.trace.add("Next version: %s, snapshot template: '%s'", tags.formatVersion(next), viper.GetString(GoopscSemverSnapshotTemplate))
+	return version, nil
+}
+
+// sanitizeIdentifier replaces characters not allowed in semver identifiers. Numeric identifiers with leading zero
+// are not valid semver, so they are prefixed e.g. branch-0123.
+func sanitizeIdentifier(s string) string {
+	s = strings.Trim(invalidIdentifierRegex.ReplaceAllString(s, "-"), "-")
+	if s == "" {
+		return "HEAD"
+	}
+	if numericIdentifierRegex.MatchString(s) {
+		return "branch-" + s
+	}
+	return s
+}
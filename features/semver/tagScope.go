@@ -0,0 +1,152 @@
+package semver
+
+import (
+	"github.com/sotomskir/goops/gitService"
+	"strings"
+)
+
+// tagScope selects git tags holding versions. Tags are expected to be in <prefix><semver> format
+// e.g. v1.2.3 for "v" prefix. Tags not matching prefix or not being valid semver are ignored.
+// When paths are set only commits touching them are taken into account.
+type tagScope struct {
+	prefix string
+	paths  []string
+	format versionFormat
+	trace  *Trace
+	build  *buildInfo
+}
+
+// versionFormat converts versions to and from strings, semver format is used when not set.
+type versionFormat interface {
+	parse(version string) (Version, error)
+	format(version Version) string
+}
+
+func (t tagScope) formatVersion(version Version) string {
+	if t.format == nil {
+		return version.String()
+	}
+	return t.format.format(version)
+}
+
+func (t tagScope) tagName(version Version) string {
+	return t.prefix + t.formatVersion(version)
+}
+
+func (t tagScope) parseVersion(version string) (Version, error) {
+	if t.format == nil {
+		return Parse(version)
+	}
+	return t.format.parse(version)
+}
+
+func (t tagScope) parseTag(tag string) (Version, error) {
+	return t.parseVersion(strings.TrimPrefix(tag, t.prefix))
+}
+
+func (t tagScope) isVersionTag(tag string) bool {
+	if !strings.HasPrefix(tag, t.prefix) {
+		return false
+	}
+	_, err := t.parseTag(tag)
+	return err == nil
+}
+
+// headTags returns version tags pointing at HEAD.
+func (t tagScope) headTags() []string {
+	tags := make([]string, 0)
+	for _, tag := range strings.Split(gitService.GetHeadTag(), "\n") {
+		tag = strings.Trim(tag, " \t")
+		if t.isVersionTag(tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// headVersion returns highest version from tags pointing at HEAD. Second value is false when HEAD is not tagged.
+func (t tagScope) headVersion() (Version, bool) {
+	var head Version
+	tagged := false
+	for _, tag := range t.headTags() {
+		version, _ := t.parseTag(tag)
+		if !tagged || head.LessThan(version) {
+			head = version
+			tagged = true
+		}
+	}
+	if tagged {
+		t.trace.add("HEAD is tagged with version: %s", t.formatVersion(head))
+	} else {
+		t.trace.add("HEAD is not tagged")
+	}
+	return head, tagged
+}
+
+// previousTag returns nearest tag matching scope or empty string when there are no such tags.
+func (t tagScope) previousTag() string {
+	return t.previousTagMatching(t.pattern())
+}
+
+// previousReleaseTag returns nearest tag matching scope which does not point at HEAD,
+// so commits of release tagged on HEAD can be listed.
+func (t tagScope) previousReleaseTag() string {
+	return t.previousTagMatching(t.pattern(), t.headTags()...)
+}
+
+// pattern returns glob pattern (without prefix) matching all tags in scope.
+func (t tagScope) pattern() string {
+	if t.prefix == "" {
+		return ""
+	}
+	return "*"
+}
+
+// previousTagMatching returns nearest tag matching scope and glob pattern (without prefix) e.g. 1.4.*
+// Excluded tags are skipped. When nearest tag is not a version, newest version tag reachable from HEAD is returned.
+func (t tagScope) previousTagMatching(pattern string, exclude ...string) string {
+	if pattern != "" {
+		pattern = t.prefix + pattern
+	}
+	tag := gitService.GetPreviousTagMatching(pattern, exclude...)
+	if tag == "" || t.isVersionTag(tag) {
+		return tag
+	}
+	t.trace.add("Skipping tag: %s, not a semantic version with prefix: '%s'", tag, t.prefix)
+	// all tags are listed at once, describing them one by one would spawn git for every skipped tag
+	tags, err := gitService.GetMergedTags(pattern)
+	if err != nil {
+		return ""
+	}
+	excluded := make(map[string]bool)
+	for _, tag := range exclude {
+		excluded[tag] = true
+	}
+	for _, tag := range tags {
+		if !excluded[tag] && t.isVersionTag(tag) {
+			return tag
+		}
+	}
+	return ""
+}
+
+// previousVersion returns version and name of previous tag. Version is 0.0.0 and tag is empty when there are no tags.
+func (t tagScope) previousVersion() (Version, string) {
+	tag := t.previousTag()
+	if tag == "" {
+		t.trace.add("Previous tag not found, using version: %s", t.formatVersion(Version{}))
+		return Version{}, ""
+	}
+	version, _ := t.parseTag(tag)
+	t.trace.add("Previous tag: %s", tag)
+	return version, tag
+}
+
+// changedSince returns true when there are commits touching scope paths since given tag.
+func (t tagScope) changedSince(tag string) (bool, error) {
+	count, err := gitService.GetCommitCount(tag, t.paths...)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
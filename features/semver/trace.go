@@ -0,0 +1,50 @@
+package semver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+)
+
+// Trace records decision path taken by strategy while computing version e.g. previous tag found,
+// branch classification, existing stable branches and applied bump.
+type Trace struct {
+	Strategy  string   `json:"strategy"`
+	Component string   `json:"component,omitempty"`
+	Steps     []string `json:"steps"`
+	Version   string   `json:"version"`
+}
+
+// add records decision step. Steps are not recorded on nil trace, so strategies can trace unconditionally.
+func (t *Trace) add(format string, args ...interface{}) {
+	step := fmt.Sprintf(format, args...)
+	logrus.Debugln(step)
+	if t == nil {
+		return
+	}
+	t.Steps = append(t.Steps, step)
+}
+
+// String returns human readable trace
+func (t *Trace) String() string {
+	var buffer bytes.Buffer
+	name := "Version"
+	if t.Component != "" {
+		name = fmt.Sprintf("Component %s version", t.Component)
+	}
+	buffer.WriteString(fmt.Sprintf("%s: %s (strategy: %s)\n", name, t.Version, t.Strategy))
+	for i, step := range t.Steps {
+		buffer.WriteString(fmt.Sprintf("  %d. %s\n", i+1, step))
+	}
+	return buffer.String()
+}
+
+// TracesToJSON returns traces as indented JSON array
+func TracesToJSON(traces []*Trace) (string, error) {
+	out, err := json.MarshalIndent(traces, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
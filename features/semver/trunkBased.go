@@ -0,0 +1,106 @@
+package semver
+
+import (
+	"fmt"
+	"github.com/sotomskir/goops/gitService"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// branchPattern is branch name with {major} and {minor} placeholders e.g. release/{major}.{minor}
+type branchPattern struct {
+	pattern string
+	regex   *regexp.Regexp
+}
+
+func newBranchPattern(pattern string) (branchPattern, error) {
+	if !strings.Contains(pattern, "{major}") || !strings.Contains(pattern, "{minor}") {
+		return branchPattern{}, fmt.Errorf("invalid branch pattern: '%s', {major} and {minor} placeholders are required", pattern)
+	}
+	expression := regexp.QuoteMeta(pattern)
+	expression = strings.Replace(expression, regexp.QuoteMeta("{major}"), "(?P<major>\\d+)", 1)
+	expression = strings.Replace(expression, regexp.QuoteMeta("{minor}"), "(?P<minor>\\d+)", 1)
+	regex, err := regexp.Compile(fmt.Sprintf("^%s$", expression))
+	if err != nil {
+		return branchPattern{}, fmt.Errorf("invalid branch pattern: '%s': %s", pattern, err)
+	}
+	return branchPattern{pattern: pattern, regex: regex}, nil
+}
+
+// format returns branch name for given major and minor version
+func (p branchPattern) format(major int, minor int) string {
+	name := strings.Replace(p.pattern, "{major}", strconv.Itoa(major), 1)
+	return strings.Replace(name, "{minor}", strconv.Itoa(minor), 1)
+}
+
+// match returns major and minor version from branch name. Last value is false when branch does not match pattern.
+func (p branchPattern) match(branch string) (int, int, bool) {
+	match := p.regex.FindStringSubmatch(branch)
+	if match == nil {
+		return 0, 0, false
+	}
+	var major, minor int
+	for i, name := range p.regex.SubexpNames() {
+		switch name {
+		case "major":
+			major, _ = strconv.Atoi(match[i])
+		case "minor":
+			minor, _ = strconv.Atoi(match[i])
+		}
+	}
+	return major, minor, true
+}
+
+// trunkBased implements versioning for trunk based development with release branches cut from trunk.
+// Trunk and other branches produce snapshot of next minor version, release branches produce patch versions.
+type trunkBased struct {
+	releaseBranch branchPattern
+}
+
+func (t trunkBased) getSemanticVersion(tags tagScope) (Version, error) {
+	if headVersion, tagged := tags.headVersion(); tagged {
+		return headVersion, nil
+	}
+	branch := gitService.GetCurrentBranchName()
+	if major, minor, ok := t.releaseBranch.match(branch); ok {
+		tags.trace.add("Branch: %s matches release branch pattern: %s", branch, t.releaseBranch.pattern)
+		return t.releaseBranchVersion(major, minor, tags)
+	}
+	tags.trace.add("Branch: %s does not match release branch pattern: %s", branch, t.releaseBranch.pattern)
+	previousVersion, _ := tags.previousVersion()
+	version := bumpMinorVersion(previousVersion)
+	tags.trace.add("Bumped minor version: %s", version)
+	branches, err := gitService.GetRemoteBranches()
+	if err != nil {
+		return Version{}, err
+	}
+	// Tags on release branches are not reachable from trunk, so next version must be higher than any release branch
+	for _, branch := range branches {
+		if major, minor, ok := t.releaseBranch.match(branch); ok {
+			released := Version{Major: major, Minor: minor}
+			if !released.LessThan(version.Release()) {
+				version = bumpMinorVersion(released)
+				tags.trace.add("Release branch: %s exists, bumped minor version: %s", branch, version)
+			}
+		}
+	}
+	return snapshotVersion(version, tags)
+}
+
+// releaseBranchVersion bumps patch of previous tag from release branch version e.g. 1.4.x for release/1.4
+func (t trunkBased) releaseBranchVersion(major int, minor int, tags tagScope) (Version, error) {
+	version := Version{Major: major, Minor: minor}
+	previousTag := tags.previousTagMatching(fmt.Sprintf("%d.%d.*", major, minor))
+	if previousTag != "" {
+		previousVersion, err := tags.parseTag(previousTag)
+		if err != nil {
+			return Version{}, err
+		}
+		version = bumpPatchVersion(previousVersion.Release())
+		tags.trace.add("Previous release tag: %s, bumped patch version: %s", previousTag, version)
+	} else {
+		tags.trace.add("No tags for release %d.%d, using version: %s", major, minor, version)
+	}
+	return snapshotVersion(version, tags)
+}
@@ -0,0 +1,158 @@
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// versionRegex is the regular expression suggested by SemVer 2.0.0 specification
+// with optional "v" prefix allowed.
+var versionRegex = regexp.MustCompile("^v?(0|[1-9]\\d*)\\.(0|[1-9]\\d*)\\.(0|[1-9]\\d*)" +
+	"(?:-((?:0|[1-9]\\d*|\\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\\.(?:0|[1-9]\\d*|\\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?" +
+	"(?:\\+([0-9a-zA-Z-]+(?:\\.[0-9a-zA-Z-]+)*))?$")
+
+// Version represents semantic version as described by https://semver.org/spec/v2.0.0.html
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease []string
+	Build      []string
+}
+
+// Parse parses semantic version string e.g. 1.2.3-rc.1+build.5
+// Leading "v" is accepted and dropped.
+func Parse(version string) (Version, error) {
+	match := versionRegex.FindStringSubmatch(strings.Trim(version, " \n\t"))
+	if match == nil {
+		return Version{}, fmt.Errorf("invalid semantic version: '%s'", version)
+	}
+	v := Version{}
+	var err error
+	if v.Major, err = strconv.Atoi(match[1]); err != nil {
+		return Version{}, fmt.Errorf("invalid major version: '%s': %s", match[1], err)
+	}
+	if v.Minor, err = strconv.Atoi(match[2]); err != nil {
+		return Version{}, fmt.Errorf("invalid minor version: '%s': %s", match[2], err)
+	}
+	if v.Patch, err = strconv.Atoi(match[3]); err != nil {
+		return Version{}, fmt.Errorf("invalid patch version: '%s': %s", match[3], err)
+	}
+	if match[4] != "" {
+		v.Prerelease = strings.Split(match[4], ".")
+	}
+	if match[5] != "" {
+		v.Build = strings.Split(match[5], ".")
+	}
+	return v, nil
+}
+
+// MustParse is like Parse but panics if version cannot be parsed.
+func MustParse(version string) Version {
+	v, err := Parse(version)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s = fmt.Sprintf("%s-%s", s, strings.Join(v.Prerelease, "."))
+	}
+	if len(v.Build) > 0 {
+		s = fmt.Sprintf("%s+%s", s, strings.Join(v.Build, "."))
+	}
+	return s
+}
+
+// Release returns version without prerelease identifiers and build metadata.
+func (v Version) Release() Version {
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+}
+
+// WithPrerelease returns copy of version with prerelease identifiers replaced.
+func (v Version) WithPrerelease(identifiers ...string) Version {
+	v.Prerelease = append([]string(nil), identifiers...)
+	return v
+}
+
+// WithBuild returns copy of version with build metadata replaced.
+func (v Version) WithBuild(identifiers ...string) Version {
+	v.Build = append([]string(nil), identifiers...)
+	return v
+}
+
+func (v Version) IsPrerelease() bool {
+	return len(v.Prerelease) > 0
+}
+
+// Compare returns -1, 0 or 1 when v has lower, equal or higher precedence than o.
+// Build metadata is ignored as required by specification.
+func (v Version) Compare(o Version) int {
+	if c := compareInt(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+func (v Version) LessThan(o Version) bool {
+	return v.Compare(o) < 0
+}
+
+func (v Version) Equal(o Version) bool {
+	return v.Compare(o) == 0
+}
+
+func comparePrerelease(a []string, b []string) int {
+	// Version without prerelease has higher precedence
+	if len(a) == 0 || len(b) == 0 {
+		return -compareInt(len(a), len(b))
+	}
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := compareIdentifier(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInt(len(a), len(b))
+}
+
+func compareIdentifier(a string, b string) int {
+	aNum, aErr := strconv.ParseUint(a, 10, 64)
+	bNum, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		if aNum == bNum {
+			return 0
+		}
+		if aNum < bNum {
+			return -1
+		}
+		return 1
+	case aErr == nil:
+		// Numeric identifiers have lower precedence than alphanumeric
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInt(a int, b int) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,80 @@
+// Copyright © 2019 Robert Sotomski <sotomski@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gerritApi
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var digestParamRegex = regexp.MustCompile("(\\w+)=(?:\"([^\"]*)\"|([^\\s,]*))")
+
+// digestAuthorization returns Authorization header answering HTTP digest challenge (RFC 2617, MD5 algorithm)
+func digestAuthorization(challenge string, method string, uri string, user string, password string) (string, error) {
+	if !strings.HasPrefix(strings.ToLower(challenge), "digest ") {
+		return "", fmt.Errorf("server did not request digest authentication, got: '%s'", challenge)
+	}
+	params := make(map[string]string)
+	for _, match := range digestParamRegex.FindAllStringSubmatch(challenge[len("digest "):], -1) {
+		params[strings.ToLower(match[1])] = match[2] + match[3]
+	}
+	if algorithm := params["algorithm"]; algorithm != "" && !strings.EqualFold(algorithm, "MD5") {
+		return "", fmt.Errorf("unsupported digest algorithm: %s", algorithm)
+	}
+	ha1 := md5Hex(fmt.Sprintf("%s:%s:%s", user, params["realm"], password))
+	ha2 := md5Hex(fmt.Sprintf("%s:%s", method, uri))
+	authorization := fmt.Sprintf(`Digest username="%s", realm="%s", nonce="%s", uri="%s"`, user, params["realm"], params["nonce"], uri)
+	if hasQopAuth(params["qop"]) {
+		cnonce, err := newCnonce()
+		if err != nil {
+			return "", err
+		}
+		nc := "00000001"
+		response := md5Hex(fmt.Sprintf("%s:%s:%s:%s:auth:%s", ha1, params["nonce"], nc, cnonce, ha2))
+		authorization += fmt.Sprintf(`, qop=auth, nc=%s, cnonce="%s", response="%s"`, nc, cnonce, response)
+	} else {
+		authorization += fmt.Sprintf(`, response="%s"`, md5Hex(fmt.Sprintf("%s:%s:%s", ha1, params["nonce"], ha2)))
+	}
+	if opaque, ok := params["opaque"]; ok {
+		authorization += fmt.Sprintf(`, opaque="%s"`, opaque)
+	}
+	return authorization, nil
+}
+
+func hasQopAuth(qop string) bool {
+	for _, option := range strings.Split(qop, ",") {
+		if strings.TrimSpace(option) == "auth" {
+			return true
+		}
+	}
+	return false
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func newCnonce() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
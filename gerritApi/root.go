@@ -21,54 +21,241 @@ import (
 	"github.com/sotomskir/goops/utils"
 	"github.com/spf13/viper"
 	"gopkg.in/resty.v1"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
 	"time"
 )
 
-type Project struct {
-	Id   string `json:"id"`
-	Key  string `json:"key"`
-	Name string `json:"name"`
+// xssiPrefix is prepended by Gerrit to every JSON response to prevent cross site script inclusion
+const xssiPrefix = ")]}'"
+
+// CommitInfo is commit of single patch set
+type CommitInfo struct {
+	Commit  string `json:"commit"`
+	Subject string `json:"subject"`
+	Message string `json:"message"`
+}
+
+// RevisionInfo is single patch set of change
+type RevisionInfo struct {
+	Number int        `json:"_number"`
+	Ref    string     `json:"ref"`
+	Commit CommitInfo `json:"commit"`
+}
+
+// ChangeInfo is change detail, revisions of all patch sets are included
+type ChangeInfo struct {
+	Id              string                  `json:"id"`
+	Project         string                  `json:"project"`
+	Branch          string                  `json:"branch"`
+	Topic           string                  `json:"topic"`
+	ChangeId        string                  `json:"change_id"`
+	Subject         string                  `json:"subject"`
+	Status          string                  `json:"status"`
+	Number          int                     `json:"_number"`
+	CurrentRevision string                  `json:"current_revision"`
+	Revisions       map[string]RevisionInfo `json:"revisions"`
+}
+
+// RelatedChange is change that depends on, or is dependency of another change
+type RelatedChange struct {
+	ChangeId       string     `json:"change_id"`
+	Commit         CommitInfo `json:"commit"`
+	ChangeNumber   int        `json:"_change_number"`
+	RevisionNumber int        `json:"_revision_number"`
+	Status         string     `json:"status"`
+}
+
+type relatedChanges struct {
+	Changes []RelatedChange `json:"changes"`
+}
+
+// ReviewInput is review of patch set, labels are votes e.g. "Verified": 1 and comments are keyed by file path
+type ReviewInput struct {
+	Message  string                    `json:"message,omitempty"`
+	Labels   map[string]int            `json:"labels,omitempty"`
+	Comments map[string][]CommentInput `json:"comments,omitempty"`
+}
+
+// CommentInput is inline comment, comment with line 0 is file comment
+type CommentInput struct {
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// ReviewResult contains labels applied by review
+type ReviewResult struct {
+	Labels map[string]int `json:"labels"`
 }
 
+var client *resty.Client
+
+// Initialize configures Gerrit client. Requests are authenticated when GOOPSC_GERRIT_USER is set,
+// GOOPSC_GERRIT_AUTH selects basic (default) or digest HTTP authentication.
 func Initialize() {
-	resty.SetHostURL(viper.GetString("GOOPSC_GERRIT_URL"))
-	resty.SetTimeout(1 * time.Minute)
+	viper.SetDefault("GOOPSC_GERRIT_AUTH", "basic")
+	client = resty.New()
+	client.SetHostURL(strings.TrimRight(viper.GetString("GOOPSC_GERRIT_URL"), "/"))
+	client.SetTimeout(1 * time.Minute)
 
 	// Headers for all request
-	resty.SetHeader("Accept", "application/json")
-	resty.SetHeaders(map[string]string{
-		"Content-Type":  "application/json",
-		"User-Agent":    "goops",
-		"Private-Token": viper.GetString("ci_gitlab_token"),
+	client.SetHeader("Accept", "application/json")
+	client.SetHeaders(map[string]string{
+		"Content-Type": "application/json",
+		"User-Agent":   "goops",
 	})
+	if isAuthenticated() && !isDigest() {
+		client.SetBasicAuth(viper.GetString("GOOPSC_GERRIT_USER"), viper.GetString("GOOPSC_GERRIT_PASSWORD"))
+	}
 }
 
 func validate() {
 	utils.ViperValidateEnv("GOOPSC_GERRIT_URL")
+	if auth := viper.GetString("GOOPSC_GERRIT_AUTH"); auth != "basic" && auth != "digest" {
+		logrus.Fatalf("unexpected GOOPSC_GERRIT_AUTH: %s, expected one of: basic, digest\n", auth)
+	}
+}
+
+func isAuthenticated() bool {
+	return viper.GetString("GOOPSC_GERRIT_USER") != ""
+}
+
+func isDigest() bool {
+	return viper.GetString("GOOPSC_GERRIT_AUTH") == "digest"
+}
+
+// IsConfigured returns true when Gerrit url is set
+func IsConfigured() bool {
+	return viper.GetString("GOOPSC_GERRIT_URL") != ""
 }
 
-func get(endpoint string, response interface{}) {
+// path prefixes endpoint with "/a" as Gerrit requires for authenticated REST API calls
+func path(endpoint string) string {
+	if isAuthenticated() {
+		return "/a" + endpoint
+	}
+	return endpoint
+}
+
+func get(endpoint string, response interface{}) error {
+	return execute(http.MethodGet, endpoint, nil, response)
+}
+
+func post(endpoint string, body interface{}, response interface{}) {
+	if err := execute(http.MethodPost, endpoint, body, response); err != nil {
+		logrus.Fatalln(err)
+	}
+}
+
+func execute(method string, endpoint string, body interface{}, response interface{}) error {
 	validate()
-	res, err := resty.R().Get(endpoint)
+	res, err := client.R().SetBody(body).Execute(method, path(endpoint))
 	if err != nil {
-		logrus.Fatalln(err)
+		return err
+	}
+
+	if res.StatusCode() == http.StatusUnauthorized && isAuthenticated() && isDigest() {
+		res = retryWithDigest(res, body)
 	}
 
 	if res.StatusCode() >= 400 {
-		logrus.Fatalf("GET: %s\nStatus code: %d\nResponse: %s\n", endpoint, res.StatusCode(), string(res.Body()))
+		return fmt.Errorf("%s: %s\nStatus code: %d\nResponse: %s", method, endpoint, res.StatusCode(), string(res.Body()))
 	}
 
-	jsonErr := json.Unmarshal(res.Body()[4:], response)
+	content := strings.TrimPrefix(string(res.Body()), xssiPrefix)
+	jsonErr := json.Unmarshal([]byte(content), response)
 
 	if jsonErr != nil {
-		logrus.Fatalf("GET: %s\nStatusCode: %d\nServer responded with invalid JSON: %s\nResponse: %s\n", endpoint, res.StatusCode(), jsonErr, string(res.Body()[4:]))
+		return fmt.Errorf("%s: %s\nStatusCode: %d\nServer responded with invalid JSON: %s\nResponse: %s", method, endpoint, res.StatusCode(), jsonErr, content)
 	}
+	return nil
+}
+
+// retryWithDigest answers digest challenge of unauthorized response by repeating the request
+func retryWithDigest(res *resty.Response, body interface{}) *resty.Response {
+	request := res.RawResponse.Request
+	authorization, err := digestAuthorization(
+		res.Header().Get("WWW-Authenticate"),
+		request.Method,
+		request.URL.RequestURI(),
+		viper.GetString("GOOPSC_GERRIT_USER"),
+		viper.GetString("GOOPSC_GERRIT_PASSWORD"),
+	)
+	if err != nil {
+		logrus.Fatalln(err)
+	}
+	retry, err := client.R().SetHeader("Authorization", authorization).SetBody(body).Execute(request.Method, request.URL.String())
+	if err != nil {
+		logrus.Fatalln(err)
+	}
+	return retry
+}
+
+func changeEndpoint(changeId string) string {
+	return fmt.Sprintf("/changes/%s", url.PathEscape(changeId))
 }
 
-func GetFiles(changeId string) {
+// GetChange returns change detail with commits of all patch sets
+func GetChange(changeId string) (ChangeInfo, error) {
+	var response ChangeInfo
+	query := url.Values{"o": {"ALL_REVISIONS", "ALL_COMMITS"}}
+	err := get(fmt.Sprintf("%s/detail?%s", changeEndpoint(changeId), query.Encode()), &response)
+	return response, err
+}
+
+// QueryChanges returns changes matching Gerrit search query with commits of all patch sets
+func QueryChanges(q string) ([]ChangeInfo, error) {
+	var response []ChangeInfo
+	query := url.Values{"q": {q}, "o": {"ALL_REVISIONS", "ALL_COMMITS"}}
+	err := get(fmt.Sprintf("/changes/?%s", query.Encode()), &response)
+	return response, err
+}
+
+// GetTopicChanges returns all changes of given topic
+func GetTopicChanges(topic string) ([]ChangeInfo, error) {
+	return QueryChanges(fmt.Sprintf("topic:\"%s\"", topic))
+}
+
+// GetRelatedChanges returns changes related to current patch set of given change
+func GetRelatedChanges(changeId string) ([]RelatedChange, error) {
+	var response relatedChanges
+	err := get(fmt.Sprintf("%s/revisions/current/related", changeEndpoint(changeId)), &response)
+	return response.Changes, err
+}
+
+// SetReview posts review with votes, message and inline comments on given revision of change
+func SetReview(changeId string, revision string, review ReviewInput) ReviewResult {
+	var response ReviewResult
+	post(fmt.Sprintf("%s/revisions/%s/review", changeEndpoint(changeId), url.PathEscape(revision)), review, &response)
+	return response
+}
+
+// GetFiles returns sorted paths of files modified in current patch set of given change
+func GetFiles(changeId string) ([]string, error) {
 	var response map[string]interface{}
-	get(fmt.Sprintf("/changes/%s/revisions/current/files/", changeId), &response)
-	for k, _ := range response {
-		fmt.Println(k)
+	if err := get(fmt.Sprintf("%s/revisions/current/files/", changeEndpoint(changeId)), &response); err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(response))
+	for k := range response {
+		files = append(files, k)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// CommitMessages returns commit messages of all patch sets of change ordered by patch set number
+func (c ChangeInfo) CommitMessages() []string {
+	revisions := make([]RevisionInfo, 0, len(c.Revisions))
+	for _, revision := range c.Revisions {
+		revisions = append(revisions, revision)
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Number < revisions[j].Number })
+	messages := make([]string, 0, len(revisions))
+	for _, revision := range revisions {
+		messages = append(messages, revision.Commit.Message)
 	}
+	return messages
 }
@@ -0,0 +1,136 @@
+// Copyright © 2019 Robert Sotomski <sotomski@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gerritApi
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"github.com/spf13/viper"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func initializeTestServer(auth string, handler http.HandlerFunc) *httptest.Server {
+	s := httptest.NewServer(handler)
+	viper.Set("GOOPSC_GERRIT_URL", s.URL)
+	viper.Set("GOOPSC_GERRIT_USER", "user")
+	viper.Set("GOOPSC_GERRIT_PASSWORD", "secret")
+	viper.Set("GOOPSC_GERRIT_AUTH", auth)
+	Initialize()
+	return s
+}
+
+const change = `)]}'
+{"id":"app~master~I1","project":"app","branch":"master","topic":"login","change_id":"I1","subject":"ABC-1 Login","_number":3,
+"revisions":{"b2":{"_number":2,"commit":{"message":"ABC-1 Login\n\nABC-3"}},"a1":{"_number":1,"commit":{"message":"ABC-1 Login"}}}}`
+
+func TestGetChangeBasicAuth(t *testing.T) {
+	server := initializeTestServer("basic", func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "secret" {
+			t.Errorf("Basic auth is invalid, got: %s %s", user, password)
+		}
+		switch r.URL.Path {
+		case "/a/changes/3/detail":
+			if o := strings.Join(r.URL.Query()["o"], ","); o != "ALL_REVISIONS,ALL_COMMITS" {
+				t.Errorf("unexpected options: %s", o)
+			}
+			fmt.Fprint(w, change)
+		case "/a/changes/":
+			if q := r.URL.Query().Get("q"); q != `topic:"login"` {
+				t.Errorf("unexpected query: %s", q)
+			}
+			fmt.Fprint(w, ")]}'\n["+change[5:]+"]")
+		case "/a/changes/3/revisions/current/related":
+			fmt.Fprint(w, `)]}'
+{"changes":[{"change_id":"I2","commit":{"subject":"ABC-4 Form"},"_change_number":4,"_revision_number":1}]}`)
+		case "/a/changes/3/revisions/current/files/":
+			fmt.Fprint(w, `)]}'
+{"b.go":{},"a.go":{}}`)
+		default:
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+	})
+	defer server.Close()
+
+	c, err := GetChange("3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := strings.Join(c.CommitMessages(), "|"); actual != "ABC-1 Login|ABC-1 Login\n\nABC-3" {
+		t.Errorf("got: %q", actual)
+	}
+	if c.Topic != "login" || c.Number != 3 {
+		t.Errorf("unexpected change: %+v", c)
+	}
+	if changes, _ := GetTopicChanges("login"); len(changes) != 1 || changes[0].ChangeId != "I1" {
+		t.Errorf("unexpected topic changes: %+v", changes)
+	}
+	if related, _ := GetRelatedChanges("3"); len(related) != 1 || related[0].Commit.Subject != "ABC-4 Form" {
+		t.Errorf("unexpected related changes: %+v", related)
+	}
+	if files, _ := GetFiles("3"); strings.Join(files, " ") != "a.go b.go" {
+		t.Errorf("got: %v", files)
+	}
+}
+
+var responseRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+func TestGetChangeDigestAuth(t *testing.T) {
+	server := initializeTestServer("digest", func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		if !strings.HasPrefix(authorization, "Digest ") {
+			w.Header().Set("WWW-Authenticate", `Digest realm="Gerrit Code Review", domain="/", qop="auth", nonce="abc"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		params := make(map[string]string)
+		for _, match := range responseRegex.FindAllStringSubmatch(authorization, -1) {
+			params[match[1]] = match[2]
+		}
+		ha1 := md5.Sum([]byte("user:Gerrit Code Review:secret"))
+		ha2 := md5.Sum([]byte("GET:" + r.URL.RequestURI()))
+		expected := md5.Sum([]byte(fmt.Sprintf("%s:abc:00000001:%s:auth:%s", hex.EncodeToString(ha1[:]), params["cnonce"], hex.EncodeToString(ha2[:]))))
+		if params["uri"] != r.URL.RequestURI() || params["response"] != hex.EncodeToString(expected[:]) {
+			t.Errorf("Digest auth is invalid, got: %s", authorization)
+		}
+		fmt.Fprint(w, change)
+	})
+	defer server.Close()
+	defer viper.Set("GOOPSC_GERRIT_AUTH", "basic")
+
+	if c, _ := GetChange("3"); c.Subject != "ABC-1 Login" {
+		t.Errorf("unexpected change: %+v", c)
+	}
+}
+
+func TestDigestAuthorization(t *testing.T) {
+	// RFC 2617 example without qop
+	actual, err := digestAuthorization(`Digest realm="testrealm@host.com", nonce="dcd98b7102dd2f0e8b11d0f600bfb0c093", opaque="5ccc069c403ebaf9f0171e9517f40e41"`,
+		"GET", "/dir/index.html", "Mufasa", "Circle Of Life")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `Digest username="Mufasa", realm="testrealm@host.com", nonce="dcd98b7102dd2f0e8b11d0f600bfb0c093", uri="/dir/index.html", response="670fd8c2df070c60b045671b8b24ff02", opaque="5ccc069c403ebaf9f0171e9517f40e41"`
+	if actual != expected {
+		t.Errorf("got: %s, want: %s", actual, expected)
+	}
+	if _, err := digestAuthorization(`Basic realm="gerrit"`, "GET", "/", "user", "secret"); err == nil {
+		t.Errorf("expected error for basic challenge")
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/sotomskir/goops/execService"
 	"github.com/spf13/viper"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -31,7 +32,7 @@ func Initialize(execServ execService.IService) {
 }
 
 func GetHeadTag() string {
-	out, err := service.Exec("git --no-pager tag --contains")
+	out, err := service.Exec("git --no-pager tag --points-at HEAD")
 	if err != nil || strings.Trim(out, " \n\t") == "nightly" {
 		return ""
 	}
@@ -39,30 +40,155 @@ func GetHeadTag() string {
 }
 
 func GetPreviousTag() string {
-	out, err := service.Exec("git describe --abbrev=0 --tags --exclude nightly")
+	return GetPreviousTagMatching("")
+}
+
+// GetPreviousTagMatching returns nearest tag matching glob pattern, skipping excluded tags.
+// Empty pattern matches all tags.
+func GetPreviousTagMatching(pattern string, exclude ...string) string {
+	cmd := "git describe --abbrev=0 --tags --exclude nightly"
+	for _, tag := range exclude {
+		cmd = fmt.Sprintf("%s --exclude %s", cmd, tag)
+	}
+	if pattern != "" {
+		cmd = fmt.Sprintf("%s --match %s", cmd, pattern)
+	}
+	out, err := service.Exec(cmd)
 	if err != nil {
 		return ""
 	}
 	return strings.Trim(out, " \n\t")
 }
 
-func StableBranchExists(major int, minor int) bool {
-	res, err := service.Exec(fmt.Sprintf("git --no-pager branch --remotes --list '*%d.%d-stable'", major, minor))
+// GetMergedTags returns tags reachable from HEAD matching glob pattern, newest first.
+// Empty pattern matches all tags.
+func GetMergedTags(pattern string) ([]string, error) {
+	cmd := "git --no-pager tag --merged HEAD --sort=-creatordate"
+	if pattern != "" {
+		cmd = fmt.Sprintf("%s --list %s", cmd, pattern)
+	}
+	out, err := service.Exec(cmd)
 	if err != nil {
-		logrus.Fatalln(res, err)
+		return nil, err
 	}
-	return res != ""
+	tags := make([]string, 0)
+	for _, tag := range strings.Split(out, "\n") {
+		if tag = strings.Trim(tag, " \t"); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+// GetCommitMessages returns messages of commits since given revision, optionally limited to commits touching paths.
+func GetCommitMessages(since string, paths ...string) ([]string, error) {
+	out, err := service.Exec(revisionRangeCommand("git --no-pager log --format=%B%x1e", since, paths))
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]string, 0)
+	for _, msg := range strings.Split(out, "\x1e") {
+		msg = strings.Trim(msg, " \n\t")
+		if msg != "" {
+			messages = append(messages, msg)
+		}
+	}
+	return messages, nil
+}
+
+// Commit is git commit with full message
+type Commit struct {
+	Sha     string
+	Message string
+}
+
+// Subject returns first line of commit message
+func (c Commit) Subject() string {
+	return strings.SplitN(c.Message, "\n", 2)[0]
+}
+
+// GetCommits returns commits since given revision, newest first, optionally limited to commits touching paths.
+func GetCommits(since string, paths ...string) ([]Commit, error) {
+	out, err := service.Exec(revisionRangeCommand("git --no-pager log --format=%H%x1f%B%x1e", since, paths))
+	if err != nil {
+		return nil, err
+	}
+	commits := make([]Commit, 0)
+	for _, entry := range strings.Split(out, "\x1e") {
+		fields := strings.SplitN(strings.Trim(entry, " \n\t"), "\x1f", 2)
+		if len(fields) < 2 {
+			continue
+		}
+		commits = append(commits, Commit{Sha: fields[0], Message: strings.Trim(fields[1], " \n\t")})
+	}
+	return commits, nil
+}
+
+// GetCommitCount returns number of commits since given revision, optionally limited to commits touching paths.
+func GetCommitCount(since string, paths ...string) (int, error) {
+	out, err := service.Exec(revisionRangeCommand("git rev-list --count", since, paths))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.Trim(out, " \n\t"))
+}
+
+func revisionRangeCommand(cmd string, since string, paths []string) string {
+	if since != "" {
+		cmd = fmt.Sprintf("%s %s..HEAD", cmd, since)
+	} else {
+		cmd = fmt.Sprintf("%s HEAD", cmd)
+	}
+	if len(paths) > 0 {
+		cmd = fmt.Sprintf("%s -- %s", cmd, strings.Join(paths, " "))
+	}
+	return cmd
 }
 
 func BranchExists(version string) bool {
-	res, err := service.Exec(fmt.Sprintf("git --no-pager branch --remotes --list '*%s*'", version))
+	res, err := service.Exec(fmt.Sprintf("git --no-pager branch --remotes --list *%s*", version))
 	if err != nil {
 		logrus.Fatalln(res, err)
 	}
 	return res != ""
 }
 
+// RemoteBranchExists returns true when branch with given name exists on any remote.
+func RemoteBranchExists(name string) bool {
+	res, err := service.Exec(fmt.Sprintf("git --no-pager branch --remotes --list */%s", name))
+	if err != nil {
+		logrus.Fatalln(res, err)
+	}
+	return strings.Trim(res, " \n\t") != ""
+}
+
+// GetRemoteBranches returns names of remote branches without remote name e.g. release/1.4 for origin/release/1.4
+func GetRemoteBranches() ([]string, error) {
+	out, err := service.Exec("git --no-pager branch --remotes --format=%(refname:lstrip=3)")
+	if err != nil {
+		return nil, err
+	}
+	branches := make([]string, 0)
+	for _, branch := range strings.Split(out, "\n") {
+		branch = strings.Trim(branch, " \t")
+		if branch != "" && branch != "HEAD" {
+			branches = append(branches, branch)
+		}
+	}
+	return branches, nil
+}
+
+// branchVariables are set to built branch name by GitLab CI, GitHub Actions, Bitbucket Pipelines and Jenkins
+var branchVariables = []string{"CI_COMMIT_REF_NAME", "GITHUB_HEAD_REF", "GITHUB_REF_NAME", "BITBUCKET_BRANCH", "BRANCH_NAME"}
+
+// GetCurrentBranchName returns branch name set by CI server, or read from git when not set.
+// CI servers usually checkout detached HEAD so git returns "HEAD".
 func GetCurrentBranchName() string {
+	for _, variable := range branchVariables {
+		if branch := viper.GetString(variable); branch != "" && !isTagRef(variable) {
+			return branch
+		}
+	}
 	branch, err := service.Exec("git rev-parse --abbrev-ref HEAD")
 	if err != nil {
 		logrus.Fatalln(branch, err)
@@ -70,6 +196,59 @@ func GetCurrentBranchName() string {
 	return branch
 }
 
+// isTagRef returns true when variable holds tag name instead of branch name because pipeline is triggered by tag.
+func isTagRef(variable string) bool {
+	switch variable {
+	case "CI_COMMIT_REF_NAME":
+		return viper.GetString("CI_COMMIT_TAG") != ""
+	case "GITHUB_REF_NAME":
+		return viper.GetString("GITHUB_REF_TYPE") == "tag"
+	}
+	return false
+}
+
+func GetHeadSha() (string, error) {
+	sha, err := service.Exec("git rev-parse HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(sha, " \n\t"), nil
+}
+
+// IsWorkingTreeClean returns false when there are uncommitted changes or untracked files.
+func IsWorkingTreeClean() (bool, error) {
+	out, err := service.Exec("git status --porcelain")
+	if err != nil {
+		return false, errors.Wrap(err, out)
+	}
+	return strings.Trim(out, " \n\t") == "", nil
+}
+
+// CreateTag creates annotated tag on HEAD. When sign is true tag is signed with key configured in git
+// (gpg or ssh depending on gpg.format) or with signingKey when not empty.
+func CreateTag(name string, message string, sign bool, signingKey string) error {
+	args := []string{"tag", "--annotate", name, "--message", message}
+	if sign {
+		args = append(args, "--sign")
+	}
+	if sign && signingKey != "" {
+		args = append(args, "--local-user", signingKey)
+	}
+	out, err := service.ExecArgs("git", args...)
+	if err != nil {
+		return errors.Wrap(err, out)
+	}
+	return nil
+}
+
+func PushTag(remote string, name string) error {
+	out, err := service.ExecArgs("git", "push", remote, fmt.Sprintf("refs/tags/%s", name))
+	if err != nil {
+		return errors.Wrap(err, out)
+	}
+	return nil
+}
+
 func GetCommitMsg() string {
 	msg, err := service.Exec("git --no-pager log -1 --pretty=%B")
 	if err != nil {
@@ -78,24 +257,57 @@ func GetCommitMsg() string {
 	return msg
 }
 
-func GetPreviousMergeRequestIid() string {
-	previousMerge, err := service.Exec("git --no-pager log -1 --merges")
+// GetPreviousMergeRequestIid returns iid of GitLab merge request from previous merge commit message
+// e.g. "See merge request group/app!12". Second value is false when merge request is not referenced.
+func GetPreviousMergeRequestIid() (string, bool, error) {
+	previousMerge, err := GetPreviousMergeMessage()
 	if err != nil {
-		logrus.Fatalln(err)
+		return "", false, err
 	}
-	if previousMerge == "" {
-		logrus.Fatalln("Merge request not found")
-	}
-	return ExtractMergeRequestIid(previousMerge)
+	iid, found := ExtractMergeRequestIid(previousMerge)
+	return iid, found, nil
 }
 
-func ExtractMergeRequestIid(s string) string {
+func ExtractMergeRequestIid(s string) (string, bool) {
 	regex := regexp.MustCompile("!(\\d+)")
 	match := regex.FindStringSubmatch(s)
 	if len(match) < 2 {
-		logrus.Fatalln("Merge request not found")
+		return "", false
+	}
+	return match[1], true
+}
+
+// GetPreviousMergeMessage returns message of previous merge commit or empty string when there are no merges.
+func GetPreviousMergeMessage() (string, error) {
+	msg, err := service.Exec("git --no-pager log -1 --merges")
+	if err != nil {
+		return "", errors.Wrap(err, msg)
+	}
+	return msg, nil
+}
+
+// GetPreviousPullRequestNumber returns number of GitHub pull request from previous merge commit message
+// e.g. "Merge pull request #12 from owner/feature"
+func GetPreviousPullRequestNumber() (int, error) {
+	previousMerge, err := GetPreviousMergeMessage()
+	if err != nil {
+		return 0, err
+	}
+	number, found := ExtractPullRequestNumber(previousMerge)
+	if !found {
+		return 0, errors.New("pull request not found in merge commit message")
+	}
+	return number, nil
+}
+
+func ExtractPullRequestNumber(s string) (int, bool) {
+	regex := regexp.MustCompile("Merge pull request #(\\d+)")
+	match := regex.FindStringSubmatch(s)
+	if len(match) < 2 {
+		return 0, false
 	}
-	return match[1]
+	number, err := strconv.Atoi(match[1])
+	return number, err == nil
 }
 
 func setupGit() {
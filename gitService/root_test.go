@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/sotomskir/goops/mockExecService"
+	"github.com/spf13/viper"
 	"testing"
 )
 
@@ -40,7 +41,7 @@ func TestGetHeadTag(t *testing.T) {
 	}
 
 	for _, table := range tables {
-		mockIService.EXPECT().Exec("git --no-pager tag --contains").Return(table.tag, table.error)
+		mockIService.EXPECT().Exec("git --no-pager tag --points-at HEAD").Return(table.tag, table.error)
 		actual := GetHeadTag()
 		if actual != table.expected {
 			t.Errorf("Tag is invalid, got: %s, want: %s.", actual, table.expected)
@@ -73,55 +74,314 @@ func TestGetPreviousTag(t *testing.T) {
 	}
 }
 
-func TestStableBranchExists(t *testing.T) {
+func TestGetPreviouslyMergedVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tables := []struct {
+		msg        string
+		version        string
+		error error
+	}{
+		{"Merge branch release-1.2.3 into master", "1.2.3",nil},
+		{"Merge branch release-11.222.3333 into master", "11.222.3333",nil},
+		{"0.0.0", "0.0.0",nil},
+	}
+
+	for _, table := range tables {
+		mockIService := mock_execService.NewMockIService(ctrl)
+		mockIService.EXPECT().Exec("git --no-pager log -n 1 --merges").Return(table.msg, table.error).AnyTimes()
+		Initialize(mockIService)
+		actual, _ := GetPreviouslyMergedVersion()
+		if actual != table.version {
+			t.Errorf("TestGetPreviouslyMergedVersion: got: '%s', want: '%s'.", actual, table.version)
+		}
+	}
+}
+
+func TestGetCommitMessages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tables := []struct {
+		since    string
+		paths    []string
+		command  string
+		output   string
+		expected []string
+	}{
+		{"1.0.0", nil, "git --no-pager log --format=%B%x1e 1.0.0..HEAD", "feat: add login\n\x1e\nfix: typo\n\nTEST-1\n\x1e", []string{"feat: add login", "fix: typo\n\nTEST-1"}},
+		{"", nil, "git --no-pager log --format=%B%x1e HEAD", "initial commit\n\x1e", []string{"initial commit"}},
+		{"2.0.0", nil, "git --no-pager log --format=%B%x1e 2.0.0..HEAD", "", []string{}},
+		{"api/1.0.0", []string{"api", "lib"}, "git --no-pager log --format=%B%x1e api/1.0.0..HEAD -- api lib", "fix: api\x1e", []string{"fix: api"}},
+	}
+
+	for _, table := range tables {
+		mockIService := mock_execService.NewMockIService(ctrl)
+		mockIService.EXPECT().Exec(table.command).Return(table.output, nil)
+		Initialize(mockIService)
+		actual, err := GetCommitMessages(table.since, table.paths...)
+		if err != nil || fmt.Sprintf("%q", actual) != fmt.Sprintf("%q", table.expected) {
+			t.Errorf("GetCommitMessages: got: %q, want: %q, err: %v.", actual, table.expected, err)
+		}
+	}
+}
+
+func TestGetCommits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIService := mock_execService.NewMockIService(ctrl)
+	mockIService.EXPECT().Exec("git --no-pager log --format=%H%x1f%B%x1e 1.0.0..HEAD -- api").Return("abc123\x1ffeat: add login\n\nABC-1\n\x1e\ndef456\x1ffix: typo\n\x1e\n", nil)
+	Initialize(mockIService)
+	actual, err := GetCommits("1.0.0", "api")
+	expected := []Commit{{"abc123", "feat: add login\n\nABC-1"}, {"def456", "fix: typo"}}
+	if err != nil || fmt.Sprintf("%q", actual) != fmt.Sprintf("%q", expected) {
+		t.Errorf("GetCommits: got: %q, want: %q, err: %v.", actual, expected, err)
+	}
+	if actual[0].Subject() != "feat: add login" {
+		t.Errorf("Subject: got: %q, want: %q.", actual[0].Subject(), "feat: add login")
+	}
+}
+
+func TestGetPreviousTagMatching(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	tables := []struct {
-		major        int
-		minor        int
-		stableBranch string
-		gitResponse  string
-		expected     bool
+		pattern  string
+		exclude  []string
+		command  string
+		tag      string
+		error    error
+		expected string
 	}{
-		{1, 1, "1.1-stable", "remotes/origin/1.1-stable", true},
-		{2, 112, "2.112-stable", "remotes/origin/2.112-stable", true},
-		{3, 333, "3.333-stable", "", false},
-		{4, 4, "4.4-stable", "", false},
+		{"v*", nil, "git describe --abbrev=0 --tags --exclude nightly --match v*", "v1.0.0", nil, "v1.0.0"},
+		{"api/*", []string{"api/latest"}, "git describe --abbrev=0 --tags --exclude nightly --exclude api/latest --match api/*", "api/1.2.0", nil, "api/1.2.0"},
+		{"", []string{"foo", "bar"}, "git describe --abbrev=0 --tags --exclude nightly --exclude foo --exclude bar", "1.0.0", nil, "1.0.0"},
+		{"v*", nil, "git describe --abbrev=0 --tags --exclude nightly --match v*", "", errors.New("no names found"), ""},
 	}
 
 	for _, table := range tables {
 		mockIService := mock_execService.NewMockIService(ctrl)
-		mockIService.EXPECT().Exec(fmt.Sprintf("git --no-pager branch --remotes --list '*%s'", table.stableBranch)).Return(table.gitResponse, nil).AnyTimes()
+		mockIService.EXPECT().Exec(table.command).Return(table.tag, table.error)
 		Initialize(mockIService)
-		actual := StableBranchExists(table.major, table.minor)
+		actual := GetPreviousTagMatching(table.pattern, table.exclude...)
 		if actual != table.expected {
-			t.Errorf("Version: %d.%d, got: %t, want: %t.", table.major, table.minor, actual, table.expected)
+			t.Errorf("Tag is invalid, got: %s, want: %s.", actual, table.expected)
 		}
 	}
 }
 
-func TestGetPreviouslyMergedVersion(t *testing.T) {
+func TestGetMergedTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIService := mock_execService.NewMockIService(ctrl)
+	mockIService.EXPECT().Exec("git --no-pager tag --merged HEAD --sort=-creatordate --list v*").Return("vlatest\nv1.2.0\n\nv1.1.0\n", nil)
+	Initialize(mockIService)
+	actual, err := GetMergedTags("v*")
+	expected := []string{"vlatest", "v1.2.0", "v1.1.0"}
+	if err != nil || fmt.Sprint(actual) != fmt.Sprint(expected) {
+		t.Errorf("GetMergedTags: got: %v, want: %v, err: %v.", actual, expected, err)
+	}
+}
+
+func TestGetCommitCount(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	tables := []struct {
-		msg        string
-		version        string
-		error error
+		since    string
+		paths    []string
+		command  string
+		output   string
+		expected int
 	}{
-		{"Merge branch release-1.2.3 into master", "1.2.3",nil},
-		{"Merge branch release-11.222.3333 into master", "11.222.3333",nil},
-		{"0.0.0", "0.0.0",nil},
+		{"1.0.0", nil, "git rev-list --count 1.0.0..HEAD", "12", 12},
+		{"", nil, "git rev-list --count HEAD", "3\n", 3},
+		{"web/2.0.0", []string{"web"}, "git rev-list --count web/2.0.0..HEAD -- web", "0", 0},
 	}
 
 	for _, table := range tables {
 		mockIService := mock_execService.NewMockIService(ctrl)
-		mockIService.EXPECT().Exec("git --no-pager log -n 1 --merges").Return(table.msg, table.error).AnyTimes()
+		mockIService.EXPECT().Exec(table.command).Return(table.output, nil)
 		Initialize(mockIService)
-		actual, _ := GetPreviouslyMergedVersion()
-		if actual != table.version {
-			t.Errorf("TestGetPreviouslyMergedVersion: got: '%s', want: '%s'.", actual, table.version)
+		actual, err := GetCommitCount(table.since, table.paths...)
+		if err != nil || actual != table.expected {
+			t.Errorf("GetCommitCount: got: %d, want: %d, err: %v.", actual, table.expected, err)
+		}
+	}
+}
+
+func TestGetCurrentBranchName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tables := []struct {
+		variables map[string]string
+		expected  string
+	}{
+		{map[string]string{}, "master"},
+		{map[string]string{"CI_COMMIT_REF_NAME": "feature/a"}, "feature/a"},
+		{map[string]string{"GITHUB_HEAD_REF": "feature/b", "GITHUB_REF_NAME": "12/merge"}, "feature/b"},
+		{map[string]string{"GITHUB_REF_NAME": "develop"}, "develop"},
+		{map[string]string{"BITBUCKET_BRANCH": "release/1.2.0"}, "release/1.2.0"},
+		{map[string]string{"BRANCH_NAME": "hotfix/1.2.1"}, "hotfix/1.2.1"},
+		// tag pipelines set ref name to tag name
+		{map[string]string{"CI_COMMIT_REF_NAME": "1.2.0", "CI_COMMIT_TAG": "1.2.0"}, "master"},
+		{map[string]string{"GITHUB_REF_NAME": "v1.2.0", "GITHUB_REF_TYPE": "tag"}, "master"},
+		{map[string]string{"GITHUB_REF_NAME": "v1.2.0", "GITHUB_REF_TYPE": "tag", "BRANCH_NAME": "main"}, "main"},
+		{map[string]string{"GITHUB_REF_NAME": "develop", "GITHUB_REF_TYPE": "branch"}, "develop"},
+	}
+
+	mockIService := mock_execService.NewMockIService(ctrl)
+	mockIService.EXPECT().Exec("git rev-parse --abbrev-ref HEAD").Return("master", nil).AnyTimes()
+	Initialize(mockIService)
+	for _, table := range tables {
+		for name, value := range table.variables {
+			viper.Set(name, value)
+		}
+		actual := GetCurrentBranchName()
+		for name := range table.variables {
+			viper.Set(name, "")
+		}
+		if actual != table.expected {
+			t.Errorf("variables: %v, got: %s, want: %s.", table.variables, actual, table.expected)
+		}
+	}
+}
+
+func TestRemoteBranchExists(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tables := []struct {
+		branch      string
+		gitResponse string
+		expected    bool
+	}{
+		{"release/1.3.0", "  origin/release/1.3.0", true},
+		{"release/1.4.0", "", false},
+	}
+
+	for _, table := range tables {
+		mockIService := mock_execService.NewMockIService(ctrl)
+		mockIService.EXPECT().Exec(fmt.Sprintf("git --no-pager branch --remotes --list */%s", table.branch)).Return(table.gitResponse, nil)
+		Initialize(mockIService)
+		actual := RemoteBranchExists(table.branch)
+		if actual != table.expected {
+			t.Errorf("Branch: %s, got: %t, want: %t.", table.branch, actual, table.expected)
+		}
+	}
+}
+
+func TestGetRemoteBranches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIService := mock_execService.NewMockIService(ctrl)
+	mockIService.EXPECT().Exec("git --no-pager branch --remotes --format=%(refname:lstrip=3)").Return("HEAD\nmain\nrelease/1.3\nrelease/1.4", nil)
+	Initialize(mockIService)
+	actual, err := GetRemoteBranches()
+	expected := []string{"main", "release/1.3", "release/1.4"}
+	if err != nil || fmt.Sprint(actual) != fmt.Sprint(expected) {
+		t.Errorf("GetRemoteBranches: got: %v, want: %v, err: %v.", actual, expected, err)
+	}
+}
+
+func TestIsWorkingTreeClean(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tables := []struct {
+		status   string
+		expected bool
+	}{
+		{"", true},
+		{" M cmd/root.go\n?? new.go", false},
+	}
+
+	for _, table := range tables {
+		mockIService := mock_execService.NewMockIService(ctrl)
+		mockIService.EXPECT().Exec("git status --porcelain").Return(table.status, nil)
+		Initialize(mockIService)
+		actual, err := IsWorkingTreeClean()
+		if err != nil || actual != table.expected {
+			t.Errorf("status: %q, got: %t, want: %t, err: %v.", table.status, actual, table.expected, err)
+		}
+	}
+}
+
+func TestCreateTag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIService := mock_execService.NewMockIService(ctrl)
+	mockIService.EXPECT().ExecArgs("git", "tag", "--annotate", "v1.2.0", "--message", "Release 1.2.0").Return("", nil)
+	mockIService.EXPECT().ExecArgs("git", "tag", "--annotate", "v1.3.0", "--message", "Release 1.3.0", "--sign").Return("", nil)
+	mockIService.EXPECT().ExecArgs("git", "tag", "--annotate", "v1.4.0", "--message", "Release 1.4.0", "--sign", "--local-user", "ABCD1234").Return("", nil)
+	mockIService.EXPECT().ExecArgs("git", "tag", "--annotate", "v1.2.0", "--message", "Release 1.2.0").Return("fatal: tag 'v1.2.0' already exists", errors.New("exit status 128"))
+	Initialize(mockIService)
+
+	if err := CreateTag("v1.2.0", "Release 1.2.0", false, "ABCD1234"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := CreateTag("v1.3.0", "Release 1.3.0", true, ""); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := CreateTag("v1.4.0", "Release 1.4.0", true, "ABCD1234"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := CreateTag("v1.2.0", "Release 1.2.0", false, ""); err == nil {
+		t.Errorf("expected error for existing tag")
+	}
+}
+
+func TestPushTag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIService := mock_execService.NewMockIService(ctrl)
+	mockIService.EXPECT().ExecArgs("git", "push", "upstream", "refs/tags/v1.2.0").Return("", nil)
+	Initialize(mockIService)
+	if err := PushTag("upstream", "v1.2.0"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestExtractPullRequestNumber(t *testing.T) {
+	tables := []struct {
+		msg      string
+		expected int
+		found    bool
+	}{
+		{"Merge pull request #12 from owner/feature\n\nABC-1 login", 12, true},
+		{"Merge branch 'feature' into 'master'\n\nSee merge request group/app!3", 0, false},
+	}
+
+	for _, table := range tables {
+		actual, found := ExtractPullRequestNumber(table.msg)
+		if actual != table.expected || found != table.found {
+			t.Errorf("msg: %q, got: %d %t, want: %d %t", table.msg, actual, found, table.expected, table.found)
+		}
+	}
+}
+
+func TestExtractMergeRequestIid(t *testing.T) {
+	tables := []struct {
+		msg      string
+		expected string
+		found    bool
+	}{
+		{"Merge branch 'feature' into 'master'\n\nSee merge request group/app!12", "12", true},
+		{"Merge branch 'feature' into 'master'", "", false},
+	}
+
+	for _, table := range tables {
+		actual, found := ExtractMergeRequestIid(table.msg)
+		if actual != table.expected || found != table.found {
+			t.Errorf("msg: %q, got: %s %t, want: %s %t", table.msg, actual, found, table.expected, table.found)
 		}
 	}
 }
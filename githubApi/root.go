@@ -0,0 +1,274 @@
+// Copyright © 2019 Robert Sotomski <sotomski@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package githubApi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/sotomskir/goops/utils"
+	"github.com/spf13/viper"
+	"gopkg.in/resty.v1"
+	"net/url"
+	"time"
+)
+
+const perPage = 100
+
+type Ref struct {
+	Ref string `json:"ref,omitempty"`
+	Sha string `json:"sha,omitempty"`
+}
+
+type PullRequest struct {
+	Number         int    `json:"number,omitempty"`
+	Title          string `json:"title,omitempty"`
+	Body           string `json:"body,omitempty"`
+	State          string `json:"state,omitempty"`
+	Head           Ref    `json:"head,omitempty"`
+	Base           Ref    `json:"base,omitempty"`
+	MergeCommitSha string `json:"merge_commit_sha,omitempty"`
+}
+
+type CommitDetails struct {
+	Message string `json:"message,omitempty"`
+}
+
+type Commit struct {
+	Sha    string        `json:"sha,omitempty"`
+	Commit CommitDetails `json:"commit,omitempty"`
+}
+
+type Tag struct {
+	Name   string `json:"name,omitempty"`
+	Commit Commit `json:"commit,omitempty"`
+}
+
+type Release struct {
+	Id         int    `json:"id,omitempty"`
+	TagName    string `json:"tag_name,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Body       string `json:"body,omitempty"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+	HtmlUrl    string `json:"html_url,omitempty"`
+}
+
+// Status is commit status, state is one of error, failure, pending, success.
+type Status struct {
+	State       string `json:"state,omitempty"`
+	TargetUrl   string `json:"target_url,omitempty"`
+	Description string `json:"description,omitempty"`
+	Context     string `json:"context,omitempty"`
+}
+
+var client *resty.Client
+
+// Initialize configures GitHub REST v3 client. GOOPSC_GITHUB_API_URL can point at GitHub Enterprise
+// e.g. https://github.example.com/api/v3
+func Initialize() {
+	viper.SetDefault("GOOPSC_GITHUB_API_URL", "https://api.github.com")
+	client = resty.New()
+	client.SetHostURL(viper.GetString("GOOPSC_GITHUB_API_URL"))
+	client.SetTimeout(1 * time.Minute)
+
+	// Headers for all request
+	client.SetHeader("Accept", "application/vnd.github.v3+json")
+	client.SetHeaders(map[string]string{
+		"Content-Type":  "application/json",
+		"User-Agent":    "goops",
+		"Authorization": fmt.Sprintf("token %s", viper.GetString("GOOPSC_GITHUB_TOKEN")),
+	})
+}
+
+func validate() {
+	utils.ViperValidateEnv("GOOPSC_GITHUB_TOKEN", "GOOPSC_GITHUB_API_URL")
+}
+
+// GetRepository returns owner/name of current repository set by GitHub Actions or Travis CI,
+// GOOPSC_GITHUB_REPOSITORY takes precedence.
+func GetRepository() string {
+	for _, variable := range []string{"GOOPSC_GITHUB_REPOSITORY", "GITHUB_REPOSITORY", "TRAVIS_REPO_SLUG"} {
+		if repo := viper.GetString(variable); repo != "" {
+			return repo
+		}
+	}
+	return ""
+}
+
+func get(endpoint string, response interface{}) {
+	if err := fetch(endpoint, response); err != nil {
+		logrus.Fatalln(err)
+	}
+}
+
+// fetch is like get but returns error instead of exiting.
+func fetch(endpoint string, response interface{}) error {
+	found, err := lookup(endpoint, response)
+	if err == nil && !found {
+		err = fmt.Errorf("GET: %s\nStatus code: 404", endpoint)
+	}
+	return err
+}
+
+// find is like get but returns false when resource does not exist.
+func find(endpoint string, response interface{}) bool {
+	found, err := lookup(endpoint, response)
+	if err != nil {
+		logrus.Fatalln(err)
+	}
+	return found
+}
+
+// lookup requests resource and decodes it into response, false is returned when resource does not exist.
+func lookup(endpoint string, response interface{}) (bool, error) {
+	validate()
+	res, err := client.R().Get(endpoint)
+	if err != nil {
+		return false, err
+	}
+	if res.StatusCode() == 404 {
+		return false, nil
+	}
+	if res.StatusCode() >= 400 {
+		return false, fmt.Errorf("GET: %s\nStatus code: %d\nResponse: %s", endpoint, res.StatusCode(), string(res.Body()))
+	}
+
+	jsonErr := json.Unmarshal(res.Body(), response)
+	if jsonErr != nil {
+		return false, fmt.Errorf("GET: %s\nStatusCode: %d\nServer responded with invalid JSON: %s\nResponse: %s", endpoint, res.StatusCode(), jsonErr, string(res.Body()))
+	}
+	return true, nil
+}
+
+func post(endpoint string, payload interface{}, response interface{}) {
+	validate()
+	res, err := client.R().SetBody(payload).Post(endpoint)
+	if err != nil {
+		logrus.Fatalln(err)
+	}
+	if res.StatusCode() >= 400 {
+		logrus.Fatalf("POST: %s\nStatus code: %d\nRequest: %#v\nResponse: %s\n", endpoint, res.StatusCode(), payload, string(res.Body()))
+	}
+
+	jsonErr := json.Unmarshal(res.Body(), response)
+	if jsonErr != nil {
+		logrus.Fatalf("POST: %s\nStatusCode: %d\nServer responded with invalid JSON: %s\nResponse: %s\n", endpoint, res.StatusCode(), jsonErr, string(res.Body()))
+	}
+}
+
+func patch(endpoint string, payload interface{}, response interface{}) {
+	validate()
+	res, err := client.R().SetBody(payload).Patch(endpoint)
+	if err != nil {
+		logrus.Fatalln(err)
+	}
+	if res.StatusCode() >= 400 {
+		logrus.Fatalf("PATCH: %s\nStatus code: %d\nRequest: %#v\nResponse: %s\n", endpoint, res.StatusCode(), payload, string(res.Body()))
+	}
+
+	jsonErr := json.Unmarshal(res.Body(), response)
+	if jsonErr != nil {
+		logrus.Fatalf("PATCH: %s\nStatusCode: %d\nServer responded with invalid JSON: %s\nResponse: %s\n", endpoint, res.StatusCode(), jsonErr, string(res.Body()))
+	}
+}
+
+func page(endpoint string, page int) string {
+	return fmt.Sprintf("%s?per_page=%d&page=%d", endpoint, perPage, page)
+}
+
+func GetPullRequest(repo string, number int) (PullRequest, error) {
+	pullRequest := PullRequest{}
+	err := fetch(fmt.Sprintf("/repos/%s/pulls/%d", repo, number), &pullRequest)
+	return pullRequest, err
+}
+
+// GetPullRequestCommits returns all commits of pull request, following pagination.
+func GetPullRequestCommits(repo string, number int) ([]Commit, error) {
+	commits := make([]Commit, 0)
+	for i := 1; ; i++ {
+		var pageCommits []Commit
+		if err := fetch(page(fmt.Sprintf("/repos/%s/pulls/%d/commits", repo, number), i), &pageCommits); err != nil {
+			return nil, err
+		}
+		commits = append(commits, pageCommits...)
+		if len(pageCommits) < perPage {
+			return commits, nil
+		}
+	}
+}
+
+// GetTags returns all repository tags, following pagination.
+func GetTags(repo string) []Tag {
+	tags := make([]Tag, 0)
+	for i := 1; ; i++ {
+		var pageTags []Tag
+		get(page(fmt.Sprintf("/repos/%s/tags", repo), i), &pageTags)
+		tags = append(tags, pageTags...)
+		if len(pageTags) < perPage {
+			return tags
+		}
+	}
+}
+
+// GetReleaseByTag returns release for given tag. Second value is false when release does not exist.
+// Draft releases are not returned by tag lookup, so all releases are searched when tag lookup fails.
+func GetReleaseByTag(repo string, tagName string) (Release, bool) {
+	release := Release{}
+	if find(fmt.Sprintf("/repos/%s/releases/tags/%s", repo, url.PathEscape(tagName)), &release) {
+		return release, true
+	}
+	for i := 1; ; i++ {
+		var pageReleases []Release
+		get(page(fmt.Sprintf("/repos/%s/releases", repo), i), &pageReleases)
+		for _, pageRelease := range pageReleases {
+			if pageRelease.TagName == tagName {
+				return pageRelease, true
+			}
+		}
+		if len(pageReleases) < perPage {
+			return Release{}, false
+		}
+	}
+}
+
+func CreateRelease(repo string, release Release) Release {
+	created := Release{}
+	post(fmt.Sprintf("/repos/%s/releases", repo), release, &created)
+	return created
+}
+
+func UpdateRelease(repo string, id int, release Release) Release {
+	updated := Release{}
+	patch(fmt.Sprintf("/repos/%s/releases/%d", repo, id), release, &updated)
+	return updated
+}
+
+// SaveRelease creates release or updates it when release for tag already exists.
+func SaveRelease(repo string, release Release) Release {
+	existing, found := GetReleaseByTag(repo, release.TagName)
+	if !found {
+		logrus.Infof("Creating release: %s\n", release.TagName)
+		return CreateRelease(repo, release)
+	}
+	logrus.Infof("Updating release: %s\n", release.TagName)
+	return UpdateRelease(repo, existing.Id, release)
+}
+
+func CreateStatus(repo string, sha string, status Status) Status {
+	created := Status{}
+	post(fmt.Sprintf("/repos/%s/statuses/%s", repo, sha), status, &created)
+	return created
+}
@@ -0,0 +1,139 @@
+// Copyright © 2019 Robert Sotomski <sotomski@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package githubApi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func initializeTestServer(handler http.HandlerFunc) *httptest.Server {
+	server := httptest.NewServer(handler)
+	viper.Set("GOOPSC_GITHUB_API_URL", server.URL)
+	viper.Set("GOOPSC_GITHUB_TOKEN", "secret")
+	Initialize()
+	return server
+}
+
+func TestGetPullRequestCommits(t *testing.T) {
+	server := initializeTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/app/pulls/7/commits" || r.Header.Get("Authorization") != "token secret" {
+			t.Errorf("unexpected request: %s %s", r.URL, r.Header.Get("Authorization"))
+		}
+		commits := make([]Commit, 0)
+		count := perPage
+		if r.URL.Query().Get("page") == "2" {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			commits = append(commits, Commit{Sha: fmt.Sprintf("%s-%d", r.URL.Query().Get("page"), i)})
+		}
+		json.NewEncoder(w).Encode(commits)
+	})
+	defer server.Close()
+
+	commits, err := GetPullRequestCommits("owner/app", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != perPage+1 || commits[perPage].Sha != "2-0" {
+		t.Errorf("Commits are invalid, got %d commits", len(commits))
+	}
+}
+
+func TestSaveRelease(t *testing.T) {
+	requests := make([]string, 0)
+	server := initializeTestServer(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, fmt.Sprintf("%s %s %s", r.Method, r.URL.EscapedPath(), r.URL.Query().Get("page")))
+		switch r.Method {
+		case http.MethodGet:
+			switch fmt.Sprintf("%s?%s", r.URL.EscapedPath(), r.URL.Query().Get("page")) {
+			case "/repos/owner/app/releases/tags/api%2F1.2.0?":
+				fmt.Fprint(w, `{"id":12,"tag_name":"api/1.2.0"}`)
+				return
+			case "/repos/owner/app/releases?1":
+				releases := make([]Release, 0)
+				for i := 0; i < perPage; i++ {
+					releases = append(releases, Release{Id: 100 + i, TagName: fmt.Sprintf("0.%d.0", i)})
+				}
+				json.NewEncoder(w).Encode(releases)
+				return
+			case "/repos/owner/app/releases?2":
+				fmt.Fprint(w, `[{"id":13,"tag_name":"1.3.0","draft":true}]`)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+		default:
+			body, _ := ioutil.ReadAll(r.Body)
+			w.Write(body)
+		}
+	})
+	defer server.Close()
+
+	release := SaveRelease("owner/app", Release{TagName: "api/1.2.0", Body: "notes"})
+	if release.Body != "notes" {
+		t.Errorf("Release is invalid, got: %#v", release)
+	}
+	SaveRelease("owner/app", Release{TagName: "1.3.0", Prerelease: true})
+	SaveRelease("owner/app", Release{TagName: "1.4.0"})
+	expected := []string{
+		"GET /repos/owner/app/releases/tags/api%2F1.2.0 ",
+		"PATCH /repos/owner/app/releases/12 ",
+		"GET /repos/owner/app/releases/tags/1.3.0 ",
+		"GET /repos/owner/app/releases 1",
+		"GET /repos/owner/app/releases 2",
+		"PATCH /repos/owner/app/releases/13 ",
+		"GET /repos/owner/app/releases/tags/1.4.0 ",
+		"GET /repos/owner/app/releases 1",
+		"GET /repos/owner/app/releases 2",
+		"POST /repos/owner/app/releases ",
+	}
+	if fmt.Sprint(requests) != fmt.Sprint(expected) {
+		t.Errorf("Requests are invalid, got: %v, want: %v", requests, expected)
+	}
+}
+
+func TestCreateStatus(t *testing.T) {
+	server := initializeTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/owner/app/statuses/abc123" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	})
+	defer server.Close()
+
+	status := CreateStatus("owner/app", "abc123", Status{State: "success", Context: "goops/version", Description: "1.2.0"})
+	if status.State != "success" || status.Context != "goops/version" {
+		t.Errorf("Status is invalid, got: %#v", status)
+	}
+}
+
+func TestGetRepository(t *testing.T) {
+	viper.Set("TRAVIS_REPO_SLUG", "travis/app")
+	viper.Set("GITHUB_REPOSITORY", "actions/app")
+	defer viper.Set("TRAVIS_REPO_SLUG", "")
+	defer viper.Set("GITHUB_REPOSITORY", "")
+	if repo := GetRepository(); repo != "actions/app" {
+		t.Errorf("got: %s, want: actions/app", repo)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright © 2019 Robert Sotomski <sotomski@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitlabApi
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/url"
+)
+
+type Milestone struct {
+	Id    int    `json:"id,omitempty"`
+	Title string `json:"title,omitempty"`
+}
+
+type ReleaseLink struct {
+	Id       int    `json:"id,omitempty"`
+	Name     string `json:"name"`
+	Url      string `json:"url"`
+	LinkType string `json:"link_type,omitempty"`
+}
+
+type ReleaseAssets struct {
+	Links []ReleaseLink `json:"links,omitempty"`
+}
+
+type Release struct {
+	Name        string        `json:"name,omitempty"`
+	TagName     string        `json:"tag_name,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Milestones  []Milestone   `json:"milestones,omitempty"`
+	Assets      ReleaseAssets `json:"assets,omitempty"`
+}
+
+// ReleaseOptions is payload of create and update release requests, milestones are referenced by title.
+type ReleaseOptions struct {
+	Name        string        `json:"name,omitempty"`
+	TagName     string        `json:"tag_name,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Ref         string        `json:"ref,omitempty"`
+	Milestones  []string      `json:"milestones,omitempty"`
+	Assets      ReleaseAssets `json:"assets,omitempty"`
+}
+
+func releaseEndpoint(projectId string, tagName string) string {
+	return fmt.Sprintf("/projects/%s/releases/%s", url.PathEscape(projectId), url.PathEscape(tagName))
+}
+
+// GetRelease returns release for given tag. Second value is false when release does not exist.
+func GetRelease(projectId string, tagName string) (Release, bool) {
+	release := Release{}
+	found := find(releaseEndpoint(projectId, tagName), &release)
+	return release, found
+}
+
+func CreateRelease(projectId string, options ReleaseOptions) Release {
+	release := Release{}
+	post(fmt.Sprintf("/projects/%s/releases", url.PathEscape(projectId)), options, &release)
+	return release
+}
+
+// UpdateRelease updates name, description and milestones of release, assets are not updated.
+func UpdateRelease(projectId string, options ReleaseOptions) Release {
+	release := Release{}
+	put(releaseEndpoint(projectId, options.TagName), ReleaseOptions{Name: options.Name, Description: options.Description, Milestones: options.Milestones}, &release)
+	return release
+}
+
+func CreateReleaseLink(projectId string, tagName string, link ReleaseLink) ReleaseLink {
+	created := ReleaseLink{}
+	post(releaseEndpoint(projectId, tagName)+"/assets/links", link, &created)
+	return created
+}
+
+// SaveRelease creates release or updates it when release for tag already exists.
+// Asset links missing in existing release are added.
+func SaveRelease(projectId string, options ReleaseOptions) Release {
+	existing, found := GetRelease(projectId, options.TagName)
+	if !found {
+		logrus.Infof("Creating release: %s\n", options.TagName)
+		return CreateRelease(projectId, options)
+	}
+	logrus.Infof("Updating release: %s\n", options.TagName)
+	release := UpdateRelease(projectId, options)
+	for _, link := range options.Assets.Links {
+		if !hasLink(existing.Assets.Links, link) {
+			release.Assets.Links = append(release.Assets.Links, CreateReleaseLink(projectId, options.TagName, link))
+		}
+	}
+	return release
+}
+
+func hasLink(links []ReleaseLink, link ReleaseLink) bool {
+	for _, l := range links {
+		if l.Name == link.Name && l.Url == link.Url {
+			return true
+		}
+	}
+	return false
+}
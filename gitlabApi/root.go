@@ -21,17 +21,25 @@ import (
 	"github.com/sotomskir/goops/utils"
 	"github.com/spf13/viper"
 	"gopkg.in/resty.v1"
-	"regexp"
 	"time"
 )
 
+const perPage = 100
+
 type MergeRequest struct {
-	Id          int    `json:"id,omitempty"`
-	Iid         int    `json:"iid,omitempty"`
-	ProjectId   int    `json:"project_id,omitempty"`
-	Title       string `json:"title,omitempty"`
-	Description string `json:"description,omitempty"`
-	State       string `json:"state,omitempty"`
+	Id           int    `json:"id,omitempty"`
+	Iid          int    `json:"iid,omitempty"`
+	ProjectId    int    `json:"project_id,omitempty"`
+	Title        string `json:"title,omitempty"`
+	Description  string `json:"description,omitempty"`
+	State        string `json:"state,omitempty"`
+	SourceBranch string `json:"source_branch,omitempty"`
+}
+
+type Commit struct {
+	Id      string `json:"id,omitempty"`
+	Title   string `json:"title,omitempty"`
+	Message string `json:"message,omitempty"`
 }
 
 type Project struct {
@@ -58,22 +66,43 @@ func validate() {
 	utils.ViperValidate("ci_api_v4_url", "server", "CI_API_V4_URL")
 }
 
-func get(endpoint string, response interface{}) {
-	validate()
-	res, err := resty.R().Get(endpoint)
+// fetch is like lookup but reports missing resource as error.
+func fetch(endpoint string, response interface{}) error {
+	found, err := lookup(endpoint, response)
+	if err == nil && !found {
+		err = fmt.Errorf("GET: %s\nStatus code: 404", endpoint)
+	}
+	return err
+}
+
+// find is like fetch but exits on error and returns false when resource does not exist.
+func find(endpoint string, response interface{}) bool {
+	found, err := lookup(endpoint, response)
 	if err != nil {
 		logrus.Fatalln(err)
 	}
+	return found
+}
 
+// lookup requests resource and decodes it into response, false is returned when resource does not exist.
+func lookup(endpoint string, response interface{}) (bool, error) {
+	validate()
+	res, err := resty.R().Get(endpoint)
+	if err != nil {
+		return false, err
+	}
+	if res.StatusCode() == 404 {
+		return false, nil
+	}
 	if res.StatusCode() >= 400 {
-		logrus.Fatalf("GET: %s\nStatus code: %d\nResponse: %s\n", endpoint, res.StatusCode(), string(res.Body()))
+		return false, fmt.Errorf("GET: %s\nStatus code: %d\nResponse: %s", endpoint, res.StatusCode(), string(res.Body()))
 	}
 
 	jsonErr := json.Unmarshal(res.Body(), response)
-
 	if jsonErr != nil {
-		logrus.Fatalf("GET: %s\nStatusCode: %d\nServer responded with invalid JSON: %s\nResponse: %s\n", endpoint, res.StatusCode(), jsonErr, string(res.Body()))
+		return false, fmt.Errorf("GET: %s\nStatusCode: %d\nServer responded with invalid JSON: %s\nResponse: %s", endpoint, res.StatusCode(), jsonErr, string(res.Body()))
 	}
+	return true, nil
 }
 
 func post(endpoint string, payload interface{}, response interface{}) {
@@ -110,26 +139,48 @@ func put(endpoint string, payload interface{}, response interface{}) {
 	}
 }
 
-func GetMergeRequestIssueKeys(projectId string, mergeRequestIId string) []string {
-	// TODO read keys from commit messages
-	mergeRequest := GetMergeRequest(projectId, mergeRequestIId)
-	titleKeys := ExtractIssueKeys(mergeRequest.Title)
-	descriptionKeys := ExtractIssueKeys(mergeRequest.Description)
-	return append(titleKeys, descriptionKeys...)
-}
-
-func ExtractIssueKeys(s string) []string {
-	regex := regexp.MustCompile("\\w+-\\d+")
-	keys := make([]string, 0)
-	match := regex.FindAllStringSubmatch(s, -1)
-	for _, v := range match {
-		keys = append(keys, v[0])
+// GetMergeRequestTexts returns merge request title, description, source branch name
+// and messages of all merge request commits.
+func GetMergeRequestTexts(projectId string, mergeRequestIId string) ([]string, error) {
+	mergeRequest, err := GetMergeRequest(projectId, mergeRequestIId)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := GetMergeRequestCommits(projectId, mergeRequestIId)
+	if err != nil {
+		return nil, err
 	}
-	return keys
+	texts := []string{mergeRequest.Title, mergeRequest.Description, mergeRequest.SourceBranch}
+	for _, commit := range commits {
+		texts = append(texts, commit.Message)
+	}
+	return texts, nil
 }
 
-func GetMergeRequest(projectId string, mergeRequestIId string) MergeRequest {
+func GetMergeRequest(projectId string, mergeRequestIId string) (MergeRequest, error) {
 	mergeRequest := MergeRequest{}
-	get(fmt.Sprintf("/projects/%s/merge_requests/%s", projectId, mergeRequestIId), &mergeRequest)
-	return mergeRequest
+	err := fetch(fmt.Sprintf("/projects/%s/merge_requests/%s", projectId, mergeRequestIId), &mergeRequest)
+	return mergeRequest, err
+}
+
+// GetCommitMergeRequests returns merge requests associated with commit, including merge requests merged by squash.
+func GetCommitMergeRequests(projectId string, sha string) ([]MergeRequest, error) {
+	mergeRequests := make([]MergeRequest, 0)
+	err := fetch(fmt.Sprintf("/projects/%s/repository/commits/%s/merge_requests", projectId, sha), &mergeRequests)
+	return mergeRequests, err
+}
+
+// GetMergeRequestCommits returns all merge request commits, following pagination.
+func GetMergeRequestCommits(projectId string, mergeRequestIId string) ([]Commit, error) {
+	commits := make([]Commit, 0)
+	for page := 1; ; page++ {
+		var pageCommits []Commit
+		if err := fetch(fmt.Sprintf("/projects/%s/merge_requests/%s/commits?per_page=%d&page=%d", projectId, mergeRequestIId, perPage, page), &pageCommits); err != nil {
+			return nil, err
+		}
+		commits = append(commits, pageCommits...)
+		if len(pageCommits) < perPage {
+			return commits, nil
+		}
+	}
 }
@@ -14,11 +14,124 @@
 
 package gitlabApi
 
-import "testing"
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
 
-func TestExtractIssueKeys(t *testing.T) {
-	keys := ExtractIssueKeys("Some merge request title related to TEST-1 and Test-2312 issues")
-	if keys[0] != "TEST-1" || keys[1] != "Test-2312" {
-		t.Errorf("Keys was incorrect, got: %#v, want: %#v\n", keys, []string{"TEST-1", "Test-2312"})
+func initializeTestServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	server := httptest.NewServer(handler)
+	viper.Set("ci_api_v4_url", server.URL)
+	viper.Set("ci_gitlab_token", "secret")
+	Initialize()
+	return server
+}
+
+func TestSaveReleaseCreate(t *testing.T) {
+	requests := make([]string, 0)
+	server := initializeTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, fmt.Sprintf("%s %s", r.Method, r.URL.EscapedPath()))
+		if r.Header.Get("Private-Token") != "secret" {
+			t.Errorf("Private-Token header is invalid, got: %s", r.Header.Get("Private-Token"))
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"404 Not Found"}`)
+		case http.MethodPost:
+			body, _ := ioutil.ReadAll(r.Body)
+			options := ReleaseOptions{}
+			json.Unmarshal(body, &options)
+			if options.TagName != "api/1.2.0" || options.Milestones[0] != "1.2" || options.Assets.Links[0].Name != "Docker image" {
+				t.Errorf("Request body is invalid, got: %s", body)
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"name":"api/1.2.0","tag_name":"api/1.2.0","milestones":[{"id":1,"title":"1.2"}]}`)
+		}
+	})
+	defer server.Close()
+
+	release := SaveRelease("42", ReleaseOptions{
+		Name:        "api/1.2.0",
+		TagName:     "api/1.2.0",
+		Description: "notes",
+		Milestones:  []string{"1.2"},
+		Assets:      ReleaseAssets{Links: []ReleaseLink{{Name: "Docker image", Url: "https://registry.example.com/api:1.2.0"}}},
+	})
+	expected := []string{"GET /projects/42/releases/api%2F1.2.0", "POST /projects/42/releases"}
+	if fmt.Sprint(requests) != fmt.Sprint(expected) {
+		t.Errorf("Requests are invalid, got: %v, want: %v", requests, expected)
+	}
+	if release.TagName != "api/1.2.0" || release.Milestones[0].Title != "1.2" {
+		t.Errorf("Release is invalid, got: %#v", release)
+	}
+}
+
+func TestSaveReleaseUpdate(t *testing.T) {
+	requests := make([]string, 0)
+	server := initializeTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, fmt.Sprintf("%s %s", r.Method, r.URL.EscapedPath()))
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, `{"tag_name":"1.2.0","assets":{"links":[{"id":1,"name":"Docker image","url":"https://registry.example.com/app:1.2.0"}]}}`)
+		case http.MethodPut:
+			fmt.Fprint(w, `{"tag_name":"1.2.0","description":"new notes","assets":{"links":[{"id":1,"name":"Docker image","url":"https://registry.example.com/app:1.2.0"}]}}`)
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id":2,"name":"Helm chart","url":"https://charts.example.com/app-1.2.0.tgz"}`)
+		}
+	})
+	defer server.Close()
+
+	release := SaveRelease("42", ReleaseOptions{
+		TagName:     "1.2.0",
+		Description: "new notes",
+		Assets: ReleaseAssets{Links: []ReleaseLink{
+			{Name: "Docker image", Url: "https://registry.example.com/app:1.2.0"},
+			{Name: "Helm chart", Url: "https://charts.example.com/app-1.2.0.tgz"},
+		}},
+	})
+	expected := []string{"GET /projects/42/releases/1.2.0", "PUT /projects/42/releases/1.2.0", "POST /projects/42/releases/1.2.0/assets/links"}
+	if fmt.Sprint(requests) != fmt.Sprint(expected) {
+		t.Errorf("Requests are invalid, got: %v, want: %v", requests, expected)
+	}
+	if release.Description != "new notes" || len(release.Assets.Links) != 2 || release.Assets.Links[1].Id != 2 {
+		t.Errorf("Release is invalid, got: %#v", release)
+	}
+}
+
+func TestGetMergeRequestTexts(t *testing.T) {
+	server := initializeTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch fmt.Sprintf("%s?%s", r.URL.Path, r.URL.Query().Get("page")) {
+		case "/projects/42/merge_requests/3?":
+			fmt.Fprint(w, `{"iid":3,"title":"ABC-2 Login","description":"Fixes ABC-1","source_branch":"feature/ABC-123-login"}`)
+		case "/projects/42/merge_requests/3/commits?1":
+			if r.URL.Query().Get("per_page") != "100" {
+				t.Errorf("unexpected page size: %s", r.URL)
+			}
+			commits := make([]Commit, 0)
+			for i := 0; i < perPage; i++ {
+				commits = append(commits, Commit{Id: fmt.Sprint(i), Message: "ABC-2 wip"})
+			}
+			json.NewEncoder(w).Encode(commits)
+		case "/projects/42/merge_requests/3/commits?2":
+			fmt.Fprint(w, `[{"id":"100","message":"ABC-4 fix test\n\nRelated to ABC-1"}]`)
+		default:
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+	})
+	defer server.Close()
+
+	texts, err := GetMergeRequestTexts("42", "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(texts) != 4+perPage || texts[0] != "ABC-2 Login" || texts[2] != "feature/ABC-123-login" || texts[len(texts)-1] != "ABC-4 fix test\n\nRelated to ABC-1" {
+		t.Errorf("Texts are invalid, got: %#v", texts)
 	}
 }
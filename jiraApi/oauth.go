@@ -0,0 +1,139 @@
+// Copyright © 2019 Robert Sotomski <sotomski@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jiraApi
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+func nonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// oauthAuthorization returns OAuth 1.0a Authorization header signed with RSA-SHA1 as required by Jira application links
+func oauthAuthorization(method string, rawUrl string) (string, error) {
+	key, err := oauthPrivateKey()
+	if err != nil {
+		return "", err
+	}
+	n, err := nonce()
+	if err != nil {
+		return "", err
+	}
+	params := map[string]string{
+		"oauth_consumer_key":     viper.GetString("GOOPSC_JIRA_OAUTH_CONSUMER_KEY"),
+		"oauth_nonce":            n,
+		"oauth_signature_method": "RSA-SHA1",
+		"oauth_timestamp":        fmt.Sprint(time.Now().Unix()),
+		"oauth_token":            viper.GetString("GOOPSC_JIRA_OAUTH_TOKEN"),
+		"oauth_version":          "1.0",
+	}
+	base, err := oauthSignatureBase(method, rawUrl, params)
+	if err != nil {
+		return "", err
+	}
+	hash := sha1.Sum([]byte(base))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA1, hash[:])
+	if err != nil {
+		return "", err
+	}
+	params["oauth_signature"] = base64.StdEncoding.EncodeToString(signature)
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	header := make([]string, 0, len(keys))
+	for _, k := range keys {
+		header = append(header, fmt.Sprintf(`%s="%s"`, oauthEscape(k), oauthEscape(params[k])))
+	}
+	return "OAuth " + strings.Join(header, ", "), nil
+}
+
+// oauthSignatureBase returns signature base string, see https://tools.ietf.org/html/rfc5849#section-3.4.1
+func oauthSignatureBase(method string, rawUrl string, oauthParams map[string]string) (string, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", err
+	}
+	params := make([]string, 0)
+	for k, values := range u.Query() {
+		for _, v := range values {
+			params = append(params, oauthEscape(k)+"="+oauthEscape(v))
+		}
+	}
+	for k, v := range oauthParams {
+		params = append(params, oauthEscape(k)+"="+oauthEscape(v))
+	}
+	sort.Strings(params)
+	baseUrl := fmt.Sprintf("%s://%s%s", strings.ToLower(u.Scheme), strings.ToLower(u.Host), u.EscapedPath())
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		oauthEscape(baseUrl),
+		oauthEscape(strings.Join(params, "&")),
+	}, "&"), nil
+}
+
+// oauthEscape percent-encodes value as required by RFC 5849
+func oauthEscape(s string) string {
+	return strings.Replace(strings.Replace(url.QueryEscape(s), "+", "%20", -1), "%7E", "~", -1)
+}
+
+// oauthPrivateKey reads PEM encoded RSA private key from GOOPSC_JIRA_OAUTH_PRIVATE_KEY, value is PEM content or file path
+func oauthPrivateKey() (*rsa.PrivateKey, error) {
+	content := viper.GetString("GOOPSC_JIRA_OAUTH_PRIVATE_KEY")
+	if !strings.HasPrefix(strings.TrimSpace(content), "-----BEGIN") {
+		file, err := ioutil.ReadFile(content)
+		if err != nil {
+			return nil, err
+		}
+		content = string(file)
+	}
+	block, _ := pem.Decode([]byte(content))
+	if block == nil {
+		return nil, errors.New("GOOPSC_JIRA_OAUTH_PRIVATE_KEY is not PEM encoded private key")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("GOOPSC_JIRA_OAUTH_PRIVATE_KEY is not RSA private key")
+	}
+	return rsaKey, nil
+}
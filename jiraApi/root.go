@@ -0,0 +1,441 @@
+// Copyright © 2019 Robert Sotomski <sotomski@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jiraApi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gopkg.in/resty.v1"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxResults = 100
+
+// Authentication modes
+const (
+	BasicAuth = "basic"
+	TokenAuth = "token"
+	PatAuth   = "pat"
+	OAuthAuth = "oauth"
+)
+
+// issueFields are fields requested when searching issues
+const issueFields = "summary,status,fixVersions,project,issuetype,resolution"
+
+type Project struct {
+	Id   string `json:"id,omitempty"`
+	Key  string `json:"key,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// StatusCategory key is one of new, indeterminate, done
+type StatusCategory struct {
+	Key  string `json:"key,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type Status struct {
+	Id             string         `json:"id,omitempty"`
+	Name           string         `json:"name,omitempty"`
+	StatusCategory StatusCategory `json:"statusCategory,omitempty"`
+}
+
+type IssueType struct {
+	Id   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type Resolution struct {
+	Id   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type Version struct {
+	Id          string `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Project     string `json:"project,omitempty"`
+	ProjectId   int    `json:"projectId,omitempty"`
+	Released    *bool  `json:"released,omitempty"`
+	Archived    *bool  `json:"archived,omitempty"`
+	ReleaseDate string `json:"releaseDate,omitempty"`
+}
+
+// IsReleased returns true when version is marked as released
+func (v Version) IsReleased() bool {
+	return v.Released != nil && *v.Released
+}
+
+type IssueFields struct {
+	Summary     string      `json:"summary,omitempty"`
+	Status      Status      `json:"status,omitempty"`
+	FixVersions []Version   `json:"fixVersions,omitempty"`
+	Project     Project     `json:"project,omitempty"`
+	IssueType   IssueType   `json:"issuetype,omitempty"`
+	Resolution  *Resolution `json:"resolution,omitempty"`
+}
+
+type Issue struct {
+	Id     string      `json:"id,omitempty"`
+	Key    string      `json:"key,omitempty"`
+	Fields IssueFields `json:"fields,omitempty"`
+}
+
+// Transition moves issue to status To
+type Transition struct {
+	Id   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	To   Status `json:"to,omitempty"`
+}
+
+type versionsPage struct {
+	Values []Version `json:"values"`
+	IsLast bool      `json:"isLast"`
+}
+
+type searchPage struct {
+	Issues        []Issue `json:"issues"`
+	Total         int     `json:"total"`
+	NextPageToken string  `json:"nextPageToken"`
+}
+
+type transitions struct {
+	Transitions []Transition `json:"transitions"`
+}
+
+var client *resty.Client
+
+// Initialize configures Jira client. Jira Cloud REST API v3 is used when GOOPSC_JIRA_CLOUD=true,
+// or when it is not set and server is hosted on atlassian.net. Jira Server REST API v2 is used otherwise.
+// GOOPSC_JIRA_AUTH selects authentication: basic (user and password), token (Jira Cloud account email and API token),
+// pat (Jira Server personal access token sent as bearer token) or oauth (OAuth 1.0a signed with RSA-SHA1).
+func Initialize() {
+	viper.SetDefault("GOOPSC_JIRA_AUTH", BasicAuth)
+	client = resty.New()
+	client.SetHostURL(strings.TrimRight(viper.GetString("GOOPSC_JIRA_SERVER_URL"), "/"))
+	client.SetTimeout(1 * time.Minute)
+
+	// Headers for all request
+	client.SetHeader("Accept", "application/json")
+	client.SetHeaders(map[string]string{
+		"Content-Type": "application/json",
+		"User-Agent":   "goops",
+	})
+	switch viper.GetString("GOOPSC_JIRA_AUTH") {
+	case BasicAuth:
+		client.SetBasicAuth(viper.GetString("GOOPSC_JIRA_USER"), viper.GetString("GOOPSC_JIRA_PASSWORD"))
+	case TokenAuth:
+		client.SetBasicAuth(viper.GetString("GOOPSC_JIRA_USER"), viper.GetString("GOOPSC_JIRA_API_TOKEN"))
+	case PatAuth:
+		client.SetAuthToken(viper.GetString("GOOPSC_JIRA_PAT"))
+	}
+}
+
+// requiredVariables returns variables required by authentication mode
+var requiredVariables = map[string][]string{
+	BasicAuth: {"GOOPSC_JIRA_USER", "GOOPSC_JIRA_PASSWORD"},
+	TokenAuth: {"GOOPSC_JIRA_USER", "GOOPSC_JIRA_API_TOKEN"},
+	PatAuth:   {"GOOPSC_JIRA_PAT"},
+	OAuthAuth: {"GOOPSC_JIRA_OAUTH_CONSUMER_KEY", "GOOPSC_JIRA_OAUTH_PRIVATE_KEY", "GOOPSC_JIRA_OAUTH_TOKEN"},
+}
+
+func validate() error {
+	auth := viper.GetString("GOOPSC_JIRA_AUTH")
+	required, ok := requiredVariables[auth]
+	if !ok {
+		return fmt.Errorf("unexpected GOOPSC_JIRA_AUTH: %s, expected one of: %s, %s, %s, %s", auth, BasicAuth, TokenAuth, PatAuth, OAuthAuth)
+	}
+	missing := make([]string, 0)
+	for _, variable := range append([]string{"GOOPSC_JIRA_SERVER_URL"}, required...) {
+		if viper.GetString(variable) == "" {
+			missing = append(missing, variable)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("required variables not set: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// IsCloud returns true when client talks to Jira Cloud
+func IsCloud() bool {
+	if cloud := viper.GetString("GOOPSC_JIRA_CLOUD"); cloud != "" {
+		return cloud == "true"
+	}
+	u, err := url.Parse(viper.GetString("GOOPSC_JIRA_SERVER_URL"))
+	return err == nil && strings.HasSuffix(u.Hostname(), ".atlassian.net")
+}
+
+func api(endpoint string) string {
+	if IsCloud() {
+		return "/rest/api/3" + endpoint
+	}
+	return "/rest/api/2" + endpoint
+}
+
+func execute(method string, endpoint string, payload interface{}, response interface{}) error {
+	if client == nil {
+		return errors.New("Jira client is not initialized")
+	}
+	if err := validate(); err != nil {
+		return err
+	}
+	logrus.Debugf("%s: %s\n", method, endpoint)
+	request := client.R().SetBody(payload)
+	if viper.GetString("GOOPSC_JIRA_AUTH") == OAuthAuth {
+		authorization, err := oauthAuthorization(method, client.HostURL+endpoint)
+		if err != nil {
+			return err
+		}
+		request.SetHeader("Authorization", authorization)
+	}
+	res, err := request.Execute(method, endpoint)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode() >= 400 {
+		return fmt.Errorf("%s: %s\nStatus code: %d\nResponse: %s", method, endpoint, res.StatusCode(), string(res.Body()))
+	}
+
+	if response == nil || len(res.Body()) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(res.Body(), response); err != nil {
+		return fmt.Errorf("%s: %s\nStatusCode: %d\nServer responded with invalid JSON: %s\nResponse: %s", method, endpoint, res.StatusCode(), err, string(res.Body()))
+	}
+	return nil
+}
+
+func get(endpoint string, response interface{}) error {
+	return execute("GET", endpoint, nil, response)
+}
+
+func post(endpoint string, payload interface{}, response interface{}) error {
+	return execute("POST", endpoint, payload, response)
+}
+
+func put(endpoint string, payload interface{}, response interface{}) error {
+	return execute("PUT", endpoint, payload, response)
+}
+
+// text returns plain text field value, Jira Cloud REST API v3 requires Atlassian Document Format
+func text(s string) interface{} {
+	if !IsCloud() {
+		return s
+	}
+	return map[string]interface{}{
+		"type":    "doc",
+		"version": 1,
+		"content": []interface{}{
+			map[string]interface{}{
+				"type":    "paragraph",
+				"content": []interface{}{map[string]string{"type": "text", "text": s}},
+			},
+		},
+	}
+}
+
+func GetProjects() ([]Project, error) {
+	var response []Project
+	err := get(api("/project"), &response)
+	return response, err
+}
+
+func GetIssue(key string) (Issue, error) {
+	var response Issue
+	query := url.Values{"fields": {issueFields}}
+	err := get(fmt.Sprintf("%s?%s", api("/issue/"+url.PathEscape(key)), query.Encode()), &response)
+	return response, err
+}
+
+// SearchIssues returns all issues matching JQL query
+func SearchIssues(jql string) ([]Issue, error) {
+	issues := make([]Issue, 0)
+	if IsCloud() {
+		token := ""
+		for {
+			query := url.Values{"jql": {jql}, "fields": {issueFields}, "maxResults": {fmt.Sprint(maxResults)}}
+			if token != "" {
+				query.Set("nextPageToken", token)
+			}
+			var response searchPage
+			if err := get(fmt.Sprintf("%s?%s", api("/search/jql"), query.Encode()), &response); err != nil {
+				return nil, err
+			}
+			issues = append(issues, response.Issues...)
+			if token = response.NextPageToken; token == "" {
+				return issues, nil
+			}
+		}
+	}
+	for {
+		query := url.Values{"jql": {jql}, "fields": {issueFields}, "maxResults": {fmt.Sprint(maxResults)}, "startAt": {fmt.Sprint(len(issues))}}
+		var response searchPage
+		if err := get(fmt.Sprintf("%s?%s", api("/search"), query.Encode()), &response); err != nil {
+			return nil, err
+		}
+		issues = append(issues, response.Issues...)
+		if len(response.Issues) == 0 || len(issues) >= response.Total {
+			return issues, nil
+		}
+	}
+}
+
+// GetProjectVersions returns all versions of project
+func GetProjectVersions(projectKey string) ([]Version, error) {
+	versions := make([]Version, 0)
+	for {
+		query := url.Values{"maxResults": {fmt.Sprint(maxResults)}, "startAt": {fmt.Sprint(len(versions))}}
+		var response versionsPage
+		if err := get(fmt.Sprintf("%s?%s", api("/project/"+url.PathEscape(projectKey)+"/version"), query.Encode()), &response); err != nil {
+			return nil, err
+		}
+		versions = append(versions, response.Values...)
+		if response.IsLast || len(response.Values) == 0 {
+			return versions, nil
+		}
+	}
+}
+
+// GetVersion returns project version with given name, second value is false when version does not exist
+func GetVersion(projectKey string, name string) (Version, bool, error) {
+	versions, err := GetProjectVersions(projectKey)
+	if err != nil {
+		return Version{}, false, err
+	}
+	for _, version := range versions {
+		if version.Name == name {
+			return version, true, nil
+		}
+	}
+	return Version{}, false, nil
+}
+
+// CreateVersion creates version in project. Jira Cloud requires project id instead of key, so it is resolved
+// from projects list.
+func CreateVersion(projectKey string, name string) (Version, error) {
+	version := Version{Name: name, Project: projectKey}
+	if IsCloud() {
+		projectId, err := getProjectId(projectKey)
+		if err != nil {
+			return Version{}, err
+		}
+		version = Version{Name: name, ProjectId: projectId}
+	}
+	var response Version
+	err := post(api("/version"), version, &response)
+	return response, err
+}
+
+func getProjectId(projectKey string) (int, error) {
+	projects, err := GetProjects()
+	if err != nil {
+		return 0, err
+	}
+	for _, project := range projects {
+		if strings.EqualFold(project.Key, projectKey) {
+			return strconv.Atoi(project.Id)
+		}
+	}
+	return 0, fmt.Errorf("project: %s does not exist in Jira", projectKey)
+}
+
+func UpdateVersion(id string, version Version) (Version, error) {
+	var response Version
+	err := put(api("/version/"+url.PathEscape(id)), version, &response)
+	return response, err
+}
+
+// AddFixVersion adds version to fix versions of issue, other fix versions are kept
+func AddFixVersion(issueKey string, versionName string) error {
+	payload := map[string]interface{}{
+		"update": map[string]interface{}{
+			"fixVersions": []interface{}{map[string]interface{}{"add": map[string]string{"name": versionName}}},
+		},
+	}
+	return put(api("/issue/"+url.PathEscape(issueKey)), payload, nil)
+}
+
+// MoveFixVersion replaces fix version from with version to, other fix versions are kept
+func MoveFixVersion(issueKey string, from string, to string) error {
+	payload := map[string]interface{}{
+		"update": map[string]interface{}{
+			"fixVersions": []interface{}{
+				map[string]interface{}{"remove": map[string]string{"name": from}},
+				map[string]interface{}{"add": map[string]string{"name": to}},
+			},
+		},
+	}
+	return put(api("/issue/"+url.PathEscape(issueKey)), payload, nil)
+}
+
+// CreateIssue creates issue in project, issue is assigned to fixVersion when not empty
+func CreateIssue(projectKey string, issueType string, summary string, description string, fixVersion string) (Issue, error) {
+	fields := map[string]interface{}{
+		"project":   map[string]string{"key": projectKey},
+		"issuetype": map[string]string{"name": issueType},
+		"summary":   summary,
+	}
+	if description != "" {
+		fields["description"] = text(description)
+	}
+	if fixVersion != "" {
+		fields["fixVersions"] = []interface{}{map[string]string{"name": fixVersion}}
+	}
+	var response Issue
+	err := post(api("/issue"), map[string]interface{}{"fields": fields}, &response)
+	return response, err
+}
+
+// GetTransitions returns transitions available for issue in its current status
+func GetTransitions(issueKey string) ([]Transition, error) {
+	var response transitions
+	err := get(api("/issue/"+url.PathEscape(issueKey)+"/transitions"), &response)
+	return response.Transitions, err
+}
+
+func DoTransition(issueKey string, transitionId string) error {
+	payload := map[string]interface{}{"transition": map[string]string{"id": transitionId}}
+	return post(api("/issue/"+url.PathEscape(issueKey)+"/transitions"), payload, nil)
+}
+
+// TransitionIssue moves issue to given status using transition available in its current status.
+// Nothing is done when issue is already in given status.
+func TransitionIssue(issueKey string, status string) error {
+	issue, err := GetIssue(issueKey)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(issue.Fields.Status.Name, status) {
+		return nil
+	}
+	available, err := GetTransitions(issueKey)
+	if err != nil {
+		return err
+	}
+	for _, transition := range available {
+		if strings.EqualFold(transition.To.Name, status) {
+			return DoTransition(issueKey, transition.Id)
+		}
+	}
+	return fmt.Errorf("issue: %s can not be transitioned from status: %s to status: %s", issueKey, issue.Fields.Status.Name, status)
+}
@@ -0,0 +1,322 @@
+// Copyright © 2019 Robert Sotomski <sotomski@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jiraApi
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func initializeTestServer(cloud bool, handler http.HandlerFunc) *httptest.Server {
+	s := httptest.NewServer(handler)
+	viper.Set("GOOPSC_JIRA_SERVER_URL", s.URL)
+	viper.Set("GOOPSC_JIRA_USER", "user")
+	viper.Set("GOOPSC_JIRA_PASSWORD", "secret")
+	viper.Set("GOOPSC_JIRA_CLOUD", fmt.Sprint(cloud))
+	Initialize()
+	return s
+}
+
+func TestGetProjectVersions(t *testing.T) {
+	server := initializeTestServer(false, func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "secret" {
+			t.Errorf("Basic auth is invalid, got: %s %s", user, password)
+		}
+		if r.URL.Path != "/rest/api/2/project/ABC/version" || r.URL.Query().Get("maxResults") != "100" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		switch r.URL.Query().Get("startAt") {
+		case "0":
+			versions := make([]Version, 0)
+			for i := 0; i < maxResults; i++ {
+				versions = append(versions, Version{Id: fmt.Sprint(i), Name: fmt.Sprintf("0.%d.0", i)})
+			}
+			json.NewEncoder(w).Encode(versionsPage{Values: versions})
+		case "100":
+			fmt.Fprint(w, `{"values":[{"id":"100","name":"1.0.0","released":true}],"isLast":true}`)
+		default:
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+	})
+	defer server.Close()
+
+	version, found, err := GetVersion("ABC", "1.0.0")
+	if err != nil || !found || version.Id != "100" || !version.IsReleased() {
+		t.Errorf("got: %+v, found: %v, err: %v", version, found, err)
+	}
+	if _, found, err := GetVersion("ABC", "2.0.0"); err != nil || found {
+		t.Errorf("expected version not found, found: %v, err: %v", found, err)
+	}
+}
+
+func TestSearchIssues(t *testing.T) {
+	tables := []struct {
+		cloud bool
+		pages map[string]string
+	}{
+		{false, map[string]string{
+			"/rest/api/2/search?0": `{"issues":[{"key":"ABC-1"},{"key":"ABC-2"}],"total":3}`,
+			"/rest/api/2/search?2": `{"issues":[{"key":"ABC-3","fields":{"status":{"name":"Done"}}}],"total":3}`,
+		}},
+		{true, map[string]string{
+			"/rest/api/3/search/jql?":     `{"issues":[{"key":"ABC-1"},{"key":"ABC-2"}],"nextPageToken":"next"}`,
+			"/rest/api/3/search/jql?next": `{"issues":[{"key":"ABC-3","fields":{"status":{"name":"Done"}}}],"isLast":true}`,
+		}},
+	}
+
+	for _, table := range tables {
+		server := initializeTestServer(table.cloud, func(w http.ResponseWriter, r *http.Request) {
+			if jql := r.URL.Query().Get("jql"); jql != "fixVersion = 1.0.0" {
+				t.Errorf("unexpected jql: %s", jql)
+			}
+			page := r.URL.Query().Get("startAt")
+			if table.cloud {
+				page = r.URL.Query().Get("nextPageToken")
+			}
+			response, ok := table.pages[fmt.Sprintf("%s?%s", r.URL.Path, page)]
+			if !ok {
+				t.Errorf("unexpected request: %s", r.URL)
+			}
+			fmt.Fprint(w, response)
+		})
+		issues, err := SearchIssues("fixVersion = 1.0.0")
+		server.Close()
+		if err != nil || len(issues) != 3 || issues[2].Fields.Status.Name != "Done" {
+			t.Errorf("cloud: %v, got: %+v, err: %v", table.cloud, issues, err)
+		}
+	}
+}
+
+func TestCreateVersion(t *testing.T) {
+	tables := []struct {
+		cloud    bool
+		endpoint string
+		expected string
+	}{
+		{false, "/rest/api/2/version", `{"name":"1.0.0","project":"ABC"}`},
+		{true, "/rest/api/3/version", `{"name":"1.0.0","projectId":10001}`},
+	}
+
+	for _, table := range tables {
+		var body string
+		server := initializeTestServer(table.cloud, func(w http.ResponseWriter, r *http.Request) {
+			switch fmt.Sprintf("%s %s", r.Method, r.URL.Path) {
+			case "GET /rest/api/3/project":
+				fmt.Fprint(w, `[{"id":"10000","key":"XYZ"},{"id":"10001","key":"ABC"}]`)
+			case "POST " + table.endpoint:
+				payload, _ := ioutil.ReadAll(r.Body)
+				body = string(payload)
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"id":"1","name":"1.0.0","released":false}`)
+			default:
+				t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			}
+		})
+		version, err := CreateVersion("ABC", "1.0.0")
+		server.Close()
+		if err != nil || version.Id != "1" || version.IsReleased() || body != table.expected {
+			t.Errorf("cloud: %v, got: %+v %s, err: %v, want: %s", table.cloud, version, body, err, table.expected)
+		}
+	}
+	viper.Set("GOOPSC_JIRA_CLOUD", "")
+}
+
+func TestCreateIssue(t *testing.T) {
+	tables := []struct {
+		cloud    bool
+		expected string
+	}{
+		{false, `{"fields":{"description":"Release notes","fixVersions":[{"name":"1.0.0"}],"issuetype":{"name":"Task"},"project":{"key":"ABC"},"summary":"Deployment"}}`},
+		{true, `{"fields":{"description":{"content":[{"content":[{"text":"Release notes","type":"text"}],"type":"paragraph"}],"type":"doc","version":1},"fixVersions":[{"name":"1.0.0"}],"issuetype":{"name":"Task"},"project":{"key":"ABC"},"summary":"Deployment"}}`},
+	}
+
+	for _, table := range tables {
+		server := initializeTestServer(table.cloud, func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasSuffix(r.URL.Path, "/issue") || r.Method != http.MethodPost {
+				t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			}
+			body, _ := ioutil.ReadAll(r.Body)
+			if string(body) != table.expected {
+				t.Errorf("cloud: %v, got: %s, want: %s", table.cloud, body, table.expected)
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id":"10","key":"ABC-10"}`)
+		})
+		issue, err := CreateIssue("ABC", "Task", "Deployment", "Release notes", "1.0.0")
+		server.Close()
+		if err != nil || issue.Key != "ABC-10" {
+			t.Errorf("cloud: %v, got: %+v, err: %v", table.cloud, issue, err)
+		}
+	}
+}
+
+func TestTransitionIssue(t *testing.T) {
+	transitioned := ""
+	server := initializeTestServer(false, func(w http.ResponseWriter, r *http.Request) {
+		switch fmt.Sprintf("%s %s", r.Method, r.URL.Path) {
+		case "GET /rest/api/2/issue/ABC-1":
+			fmt.Fprint(w, `{"key":"ABC-1","fields":{"status":{"name":"In Progress"}}}`)
+		case "GET /rest/api/2/issue/ABC-2":
+			fmt.Fprint(w, `{"key":"ABC-2","fields":{"status":{"name":"Done"}}}`)
+		case "GET /rest/api/2/issue/ABC-1/transitions":
+			fmt.Fprint(w, `{"transitions":[{"id":"21","name":"Review","to":{"name":"In Review"}},{"id":"31","name":"Finish","to":{"name":"Done"}}]}`)
+		case "POST /rest/api/2/issue/ABC-1/transitions":
+			body, _ := ioutil.ReadAll(r.Body)
+			transitioned = string(body)
+			w.WriteHeader(http.StatusNoContent)
+		case "GET /rest/api/2/issue/ABC-3":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errorMessages":["Issue does not exist"]}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+	})
+	defer server.Close()
+
+	if err := TransitionIssue("ABC-1", "done"); err != nil || transitioned != `{"transition":{"id":"31"}}` {
+		t.Errorf("got: %s, err: %v", transitioned, err)
+	}
+	if err := TransitionIssue("ABC-2", "Done"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := TransitionIssue("ABC-1", "Closed"); err == nil {
+		t.Errorf("expected error for unavailable transition")
+	}
+	if err := TransitionIssue("ABC-3", "Done"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+}
+
+func TestIsCloud(t *testing.T) {
+	defer viper.Set("GOOPSC_JIRA_CLOUD", "")
+	defer viper.Set("GOOPSC_JIRA_SERVER_URL", "")
+	tables := []struct {
+		cloud    string
+		url      string
+		expected bool
+	}{
+		{"", "https://example.atlassian.net", true},
+		{"", "https://jira.example.com", false},
+		{"true", "https://jira.example.com", true},
+		{"false", "https://example.atlassian.net", false},
+	}
+	for _, table := range tables {
+		viper.Set("GOOPSC_JIRA_CLOUD", table.cloud)
+		viper.Set("GOOPSC_JIRA_SERVER_URL", table.url)
+		if actual := IsCloud(); actual != table.expected {
+			t.Errorf("got: %v, want: %v, %v", actual, table.expected, table)
+		}
+	}
+}
+
+var oauthParamRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+func TestAuth(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+
+	tables := []struct {
+		auth      string
+		variables map[string]string
+		verify    func(r *http.Request) bool
+	}{
+		{BasicAuth, map[string]string{"GOOPSC_JIRA_USER": "user", "GOOPSC_JIRA_PASSWORD": "secret"}, func(r *http.Request) bool {
+			user, password, ok := r.BasicAuth()
+			return ok && user == "user" && password == "secret"
+		}},
+		{TokenAuth, map[string]string{"GOOPSC_JIRA_USER": "ci@example.com", "GOOPSC_JIRA_API_TOKEN": "token"}, func(r *http.Request) bool {
+			user, password, ok := r.BasicAuth()
+			return ok && user == "ci@example.com" && password == "token"
+		}},
+		{PatAuth, map[string]string{"GOOPSC_JIRA_PAT": "pat"}, func(r *http.Request) bool {
+			return r.Header.Get("Authorization") == "Bearer pat"
+		}},
+		{OAuthAuth, map[string]string{"GOOPSC_JIRA_OAUTH_CONSUMER_KEY": "goops", "GOOPSC_JIRA_OAUTH_PRIVATE_KEY": privateKey, "GOOPSC_JIRA_OAUTH_TOKEN": "access"}, func(r *http.Request) bool {
+			params := make(map[string]string)
+			for _, match := range oauthParamRegex.FindAllStringSubmatch(r.Header.Get("Authorization"), -1) {
+				params[match[1]], _ = url.QueryUnescape(match[2])
+			}
+			signature, _ := base64.StdEncoding.DecodeString(params["oauth_signature"])
+			delete(params, "oauth_signature")
+			base, _ := oauthSignatureBase(r.Method, fmt.Sprintf("http://%s%s", r.Host, r.URL.RequestURI()), params)
+			hash := sha1.Sum([]byte(base))
+			return params["oauth_consumer_key"] == "goops" && params["oauth_token"] == "access" &&
+				rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA1, hash[:], signature) == nil
+		}},
+	}
+
+	defer viper.Set("GOOPSC_JIRA_AUTH", BasicAuth)
+	for _, table := range tables {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !table.verify(r) {
+				t.Errorf("auth: %s, invalid authorization: %s", table.auth, r.Header.Get("Authorization"))
+			}
+			fmt.Fprint(w, `{"key":"ABC-1"}`)
+		}))
+		viper.Set("GOOPSC_JIRA_SERVER_URL", server.URL)
+		viper.Set("GOOPSC_JIRA_CLOUD", "false")
+		viper.Set("GOOPSC_JIRA_AUTH", table.auth)
+		for k, v := range table.variables {
+			viper.Set(k, v)
+		}
+		Initialize()
+		_, err := GetIssue("ABC-1")
+		server.Close()
+		if err != nil {
+			t.Errorf("auth: %s, unexpected error: %s", table.auth, err)
+		}
+		for k := range table.variables {
+			viper.Set(k, "")
+		}
+		if _, err := GetIssue("ABC-1"); err == nil || !strings.Contains(err.Error(), "required variables not set") {
+			t.Errorf("auth: %s, expected missing variables error, got: %v", table.auth, err)
+		}
+	}
+}
+
+func TestOAuthSignatureBase(t *testing.T) {
+	// RFC 5849 section 3.4.1.1 example
+	base, err := oauthSignatureBase("POST", "http://Example.com/request?b5=%3D%253D&a3=a&c%40=&a2=r%20b", map[string]string{
+		"oauth_consumer_key":     "9djdj82h48djs9d2",
+		"oauth_token":            "kkk9d7dh3k39sjv7",
+		"oauth_signature_method": "HMAC-SHA1",
+		"oauth_timestamp":        "137131201",
+		"oauth_nonce":            "7d8f3e4a",
+	})
+	expected := "POST&http%3A%2F%2Fexample.com%2Frequest&a2%3Dr%2520b%26a3%3Da%26b5%3D%253D%25253D%26c%2540%3D%26" +
+		"oauth_consumer_key%3D9djdj82h48djs9d2%26oauth_nonce%3D7d8f3e4a%26oauth_signature_method%3DHMAC-SHA1%26" +
+		"oauth_timestamp%3D137131201%26oauth_token%3Dkkk9d7dh3k39sjv7"
+	if err != nil || base != expected {
+		t.Errorf("got: %s, err: %v, want: %s", base, err, expected)
+	}
+}
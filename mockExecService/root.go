@@ -47,6 +47,26 @@ func (mr *MockIServiceMockRecorder) Exec(cmd interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exec", reflect.TypeOf((*MockIService)(nil).Exec), cmd)
 }
 
+// ExecArgs mocks base method
+func (m *MockIService) ExecArgs(name string, args ...string) (string, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{name}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExecArgs", varargs...)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecArgs indicates an expected call of ExecArgs
+func (mr *MockIServiceMockRecorder) ExecArgs(name interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{name}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecArgs", reflect.TypeOf((*MockIService)(nil).ExecArgs), varargs...)
+}
+
 // LogExec mocks base method
 func (m *MockIService) LogExec(cmd string) {
 	m.ctrl.T.Helper()